@@ -2,18 +2,33 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"os"
+	_ "time/tzdata" // data zona waktu user, tidak bergantung pada OS
 
 	"github.com/gin-gonic/gin"
 	"github.com/sholllll662/invoice-backend/config"
 	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/jobs"
 	"github.com/sholllll662/invoice-backend/middlewares"
 	"github.com/sholllll662/invoice-backend/routes"
+	"github.com/sholllll662/invoice-backend/utils"
 )
 
 func main() {
 	config.LoadEnv()
 	database.ConnectDB()
 
+	// Kurs dibaca dari file lokal, tidak ada feed kurs online
+	if path := os.Getenv("FX_RATES_FILE"); path != "" {
+		if err := utils.LoadExchangeRates(path, os.Getenv("FX_REFERENCE_CURRENCY")); err != nil {
+			log.Println("⚠️ Gagal memuat file kurs:", err)
+		}
+	}
+
+	// Job latar belakang: invoice berulang
+	jobs.Start()
+
 	r := gin.Default()
 
 	r.Use(middlewares.CORSMiddleware())
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/pdf"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+const maxLogoSize = 1 << 20 // 1 MB
+
+type BusinessProfileRequest struct {
+	LegalName         string `json:"legal_name" binding:"max=200"`
+	Address           string `json:"address" binding:"max=1000"`
+	TaxID             string `json:"tax_id" binding:"max=50"`
+	Phone             string `json:"phone" binding:"max=50"`
+	Email             string `json:"email" binding:"omitempty,email"`
+	BankName          string `json:"bank_name" binding:"max=100"`
+	BankAccountName   string `json:"bank_account_name" binding:"max=200"`
+	BankAccountNumber string `json:"bank_account_number" binding:"max=50"`
+	FooterText        string `json:"footer_text" binding:"max=2000"`
+}
+
+func GetBusinessProfile(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	profile, err := services.LoadBusinessProfile(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil profil usaha"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"business_profile": profile})
+}
+
+// UpdateBusinessProfile mengganti seluruh data teks profil usaha, logo diatur
+// lewat endpoint terpisah
+func UpdateBusinessProfile(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req BusinessProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	profile, err := services.LoadBusinessProfile(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil profil usaha"})
+		return
+	}
+
+	profile.LegalName = strings.TrimSpace(req.LegalName)
+	profile.Address = strings.TrimSpace(req.Address)
+	profile.TaxID = strings.TrimSpace(req.TaxID)
+	profile.Phone = strings.TrimSpace(req.Phone)
+	profile.Email = strings.TrimSpace(req.Email)
+	profile.BankName = strings.TrimSpace(req.BankName)
+	profile.BankAccountName = strings.TrimSpace(req.BankAccountName)
+	profile.BankAccountNumber = strings.TrimSpace(req.BankAccountNumber)
+	profile.FooterText = strings.TrimSpace(req.FooterText)
+
+	if err := database.DB.Save(&profile).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan profil usaha"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Profil usaha berhasil disimpan", "business_profile": profile})
+}
+
+func GetBusinessProfileLogo(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	profile, err := services.LoadBusinessProfile(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil profil usaha"})
+		return
+	}
+	if !profile.HasLogo {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Logo belum diunggah"})
+		return
+	}
+
+	c.Data(http.StatusOK, profile.LogoContentType, profile.Logo)
+}
+
+// UploadBusinessProfileLogo menerima logo PNG atau JPEG maksimal 1 MB pada field logo
+func UploadBusinessProfileLogo(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	fileHeader, err := c.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file logo wajib diunggah pada field logo"})
+		return
+	}
+	if fileHeader.Size > maxLogoSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ukuran logo maksimal 1 MB"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file tidak bisa dibaca"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxLogoSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file tidak bisa dibaca"})
+		return
+	}
+
+	// Jenis gambar ditentukan dari isi file, bukan dari nama atau header upload
+	contentType := http.DetectContentType(data)
+	if err := pdf.ValidateLogo(data, contentType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := services.LoadBusinessProfile(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil profil usaha"})
+		return
+	}
+	profile.Logo = data
+	profile.LogoContentType = contentType
+	profile.HasLogo = true
+
+	if err := database.DB.Save(&profile).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan logo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logo berhasil disimpan", "business_profile": profile})
+}
+
+func DeleteBusinessProfileLogo(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	result := database.DB.Model(&models.BusinessProfile{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"logo": nil, "logo_content_type": ""})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus logo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logo berhasil dihapus"})
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+type CatalogItemRequest struct {
+	SKU         string       `json:"sku"`
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description"`
+	UnitPrice   models.Money `json:"unit_price" binding:"gte=0"`
+	Unit        string       `json:"unit"`
+	TaxRateID   *uint        `json:"tax_rate_id"`
+}
+
+// skuTakenMessage dipakai baik saat pengecekan awal maupun saat index unik
+// menolak SKU yang disimpan bersamaan oleh request lain
+func skuTakenMessage(sku string) string {
+	return "SKU " + sku + " sudah dipakai item lain"
+}
+
+// validateCatalogItem memastikan SKU belum dipakai item lain dan tarif pajak
+// default milik user yang sama. excludeID diisi saat update.
+func validateCatalogItem(userID uint, input *CatalogItemRequest, excludeID uint) (int, string) {
+	input.SKU = strings.TrimSpace(input.SKU)
+	if input.SKU != "" {
+		var count int64
+		if err := database.DB.Model(&models.CatalogItem{}).
+			Where("user_id = ? AND LOWER(sku) = LOWER(?) AND id <> ?", userID, input.SKU, excludeID).
+			Count(&count).Error; err != nil {
+			return http.StatusInternalServerError, "Gagal memeriksa SKU"
+		}
+		if count > 0 {
+			return http.StatusConflict, skuTakenMessage(input.SKU)
+		}
+	}
+
+	if input.TaxRateID != nil {
+		var count int64
+		if err := database.DB.Model(&models.TaxRate{}).
+			Where("id = ? AND user_id = ?", *input.TaxRateID, userID).
+			Count(&count).Error; err != nil {
+			return http.StatusInternalServerError, "Gagal memeriksa tarif pajak"
+		}
+		if count == 0 {
+			return http.StatusBadRequest, "Tarif pajak tidak ditemukan"
+		}
+	}
+	return 0, ""
+}
+
+func CreateCatalogItem(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input CatalogItemRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, message := validateCatalogItem(userID, &input, 0); status != 0 {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	item := models.CatalogItem{
+		UserID:      userID,
+		SKU:         input.SKU,
+		Name:        input.Name,
+		Description: input.Description,
+		UnitPrice:   input.UnitPrice,
+		Unit:        input.Unit,
+		TaxRateID:   input.TaxRateID,
+	}
+
+	if err := database.DB.Create(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": skuTakenMessage(item.SKU)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan item katalog"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Item katalog berhasil ditambahkan", "catalog_item": item})
+}
+
+func GetCatalogItems(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Where("user_id = ?", userID)
+	if search := c.Query("search"); search != "" {
+		pattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(sku) LIKE ?", pattern, pattern)
+	}
+
+	var items []models.CatalogItem
+	if err := query.Order("name ASC").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data katalog"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"catalog_items": items})
+}
+
+func GetCatalogItemByID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var item models.CatalogItem
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item katalog tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func UpdateCatalogItem(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var item models.CatalogItem
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item katalog tidak ditemukan"})
+		return
+	}
+
+	var input CatalogItemRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, message := validateCatalogItem(userID, &input, item.ID); status != 0 {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	// Invoice lama menyimpan salinan nama dan harga, jadi perubahan hanya berlaku untuk invoice baru
+	item.SKU = input.SKU
+	item.Name = input.Name
+	item.Description = input.Description
+	item.UnitPrice = input.UnitPrice
+	item.Unit = input.Unit
+	item.TaxRateID = input.TaxRateID
+
+	if err := database.DB.Save(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": skuTakenMessage(item.SKU)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update item katalog"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item katalog berhasil diupdate", "catalog_item": item})
+}
+
+func DeleteCatalogItem(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var item models.CatalogItem
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item katalog tidak ditemukan"})
+		return
+	}
+
+	if err := database.DB.Delete(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus item katalog"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item katalog berhasil dihapus"})
+}
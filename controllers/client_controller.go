@@ -3,12 +3,23 @@ package controller
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sholllll662/invoice-backend/database"
 	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
 )
 
+// ClientRequest dipakai oleh tambah, update dan import client
+type ClientRequest struct {
+	Nama     string `json:"nama" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	NoTlp    string `json:"no_tlp" binding:"required"`
+	Currency string `json:"currency"`
+}
+
 func CreateClient(c *gin.Context) {
 	// amnil user ID dari context
 	userIDInterface, exists := c.Get("userID")
@@ -19,23 +30,27 @@ func CreateClient(c *gin.Context) {
 	userID := userIDInterface.(uint)
 
 	// bind input json
-	var input struct {
-		Nama  string `json:"nama" binding:"required"`
-		Email string `json:"email" binding:"required,email"`
-		NoTlp string `json:"no_tlp" binding:"required"`
-	}
+	var input ClientRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
 		return
 	}
 
+	// mata uang default invoice untuk client ini (opsional)
+	currency := utils.NormalizeCurrency(input.Currency)
+	if input.Currency != "" && currency == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kode mata uang harus 3 huruf, misalnya IDR atau USD"})
+		return
+	}
+
 	// buat client baru
 	client := models.Client{
-		UserID: userID,
-		Nama:   input.Nama,
-		Email:  input.Email,
-		NoTlp:  input.NoTlp,
+		UserID:   userID,
+		Nama:     input.Nama,
+		Email:    input.Email,
+		NoTlp:    input.NoTlp,
+		Currency: currency,
 	}
 
 	if err := database.DB.Create(&client).Error; err != nil {
@@ -49,6 +64,13 @@ func CreateClient(c *gin.Context) {
 	})
 }
 
+// clientSortFields adalah field yang bisa dipakai di ?sort= daftar client
+var clientSortFields = map[string]string{
+	"name":       "LOWER(nama)",
+	"email":      "LOWER(email)",
+	"created_at": "created_at",
+}
+
 func GetClients(c *gin.Context) {
 	// Ambil user ID dari context (hasil middleware)
 	userIDInterface, exists := c.Get("userID")
@@ -62,18 +84,36 @@ func GetClients(c *gin.Context) {
 	search := c.DefaultQuery("search", "")
 
 	var clients []models.Client
-	query := database.DB.Where("user_id = ?", userID)
+	query := database.DB.Model(&models.Client{}).Where("user_id = ?", userID)
 
 	if search != "" {
 		query = query.Where("LOWER(nama) LIKE ?", "%"+strings.ToLower(search)+"%")
 	}
 
-	if err := query.Order("created_at desc").Find(&clients).Error; err != nil {
+	if currencies := queryList(c, "currency"); len(currencies) > 0 {
+		for i := range currencies {
+			currencies[i] = strings.ToUpper(currencies[i])
+		}
+		query = query.Where("currency IN ?", currencies)
+	}
+
+	list, ok := parseListQuery(c, clientSortFields, "created_at DESC", "id")
+	if !ok {
+		return
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung client"})
+		return
+	}
+
+	if err := list.Apply(query).Find(&clients).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data client"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"clients": clients})
+	c.JSON(http.StatusOK, gin.H{"clients": clients, "pagination": list.Pagination(total)})
 }
 
 func UpdateClient(c *gin.Context) {
@@ -86,22 +126,45 @@ func UpdateClient(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		Nama  string `json:"nama" binding:"required"`
-		Email string `json:"email" binding:"required,email"`
-		NoTlp string `json:"no_tlp" binding:"required"`
-	}
+	var input ClientRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	currency := utils.NormalizeCurrency(input.Currency)
+	if input.Currency != "" && currency == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kode mata uang harus 3 huruf, misalnya IDR atau USD"})
+		return
+	}
+
+	emailChanged := !strings.EqualFold(client.Email, input.Email)
 	client.Nama = input.Nama
 	client.Email = input.Email
 	client.NoTlp = input.NoTlp
-
-	if err := database.DB.Save(&client).Error; err != nil {
+	client.Currency = currency
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&client).Error; err != nil {
+			return err
+		}
+		if !emailChanged {
+			return nil
+		}
+
+		// Login portal memakai email client, jadi ikut diganti. Undangan yang
+		// belum dipakai dikirim ke alamat lama sehingga dibatalkan.
+		if err := tx.Model(&models.ClientAccount{}).
+			Where("client_id = ?", client.ID).
+			Update("email", client.Email).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ClientAccount{}).
+			Where("client_id = ? AND activated_at IS NULL", client.ID).
+			Updates(map[string]interface{}{"invite_nonce": "", "invite_expires_at": nil}).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update client"})
 		return
 	}
@@ -124,5 +187,13 @@ func DeleteClient(c *gin.Context) {
 		return
 	}
 
+	// Client yang dihapus tidak bisa lagi masuk ke portal
+	if err := database.DB.Model(&models.ClientAccount{}).
+		Where("client_id = ? AND disabled_at IS NULL", client.ID).
+		Update("disabled_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut akses portal client"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Client berhasil dihapus"})
 }
@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Batas ukuran file dan jumlah baris import client
+const (
+	maxClientImportSize = 5 << 20
+	maxClientImportRows = 5000
+)
+
+// Hasil per baris import client
+const (
+	ImportCreated  = "created"
+	ImportUpdated  = "updated"
+	ImportSkipped  = "skipped"
+	ImportRejected = "rejected"
+)
+
+// clientImportColumns memetakan judul kolom CSV (huruf kecil) ke field ClientRequest
+var clientImportColumns = map[string]string{
+	"nama":      "nama",
+	"name":      "nama",
+	"email":     "email",
+	"no_tlp":    "no_tlp",
+	"telepon":   "no_tlp",
+	"phone":     "no_tlp",
+	"currency":  "currency",
+	"mata_uang": "currency",
+}
+
+type ClientImportRow struct {
+	Row      int      `json:"row"` // nomor baris di file, judul kolom = baris 1
+	Email    string   `json:"email"`
+	Action   string   `json:"action"`
+	ClientID uint     `json:"client_id,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// clientFieldError menerjemahkan error validasi binding menjadi pesan per kolom
+func clientFieldError(fieldErr validator.FieldError) string {
+	name := fieldErr.Field()
+	if field, ok := reflect.TypeOf(ClientRequest{}).FieldByName(fieldErr.StructField()); ok {
+		name = strings.Split(field.Tag.Get("json"), ",")[0]
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return name + " wajib diisi"
+	case "email":
+		return name + " bukan alamat email yang valid"
+	default:
+		return fmt.Sprintf("%s tidak valid (%s)", name, fieldErr.Tag())
+	}
+}
+
+// validateClientRequest memakai aturan binding yang sama dengan CreateClient
+func validateClientRequest(input *ClientRequest) []string {
+	var messages []string
+	if err := binding.Validator.ValidateStruct(input); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if errors.As(err, &fieldErrs) {
+			for _, fieldErr := range fieldErrs {
+				messages = append(messages, clientFieldError(fieldErr))
+			}
+		} else {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	if input.Currency != "" {
+		currency := utils.NormalizeCurrency(input.Currency)
+		if currency == "" {
+			messages = append(messages, "kode mata uang harus 3 huruf, misalnya IDR atau USD")
+		}
+		input.Currency = currency
+	}
+	return messages
+}
+
+// readClientCSV membaca file CSV berjudul kolom. Pemisah ";" (ekspor Excel
+// berbahasa Indonesia) dikenali otomatis.
+func readClientCSV(data []byte) ([]map[string]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	firstLine, _, _ := bufio.NewReader(bytes.NewReader(data)).ReadLine()
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file CSV kosong")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file CSV tidak valid: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	found := map[string]bool{}
+	for i, title := range header {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(title)), " ", "_")
+		columns[i] = clientImportColumns[key]
+		found[columns[i]] = true
+	}
+	for _, required := range []string{"nama", "email", "no_tlp"} {
+		if !found[required] {
+			return nil, fmt.Errorf("kolom %s tidak ditemukan di judul CSV", required)
+		}
+	}
+
+	var records []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("file CSV tidak valid: %w", err)
+		}
+		if len(records) >= maxClientImportRows {
+			return nil, fmt.Errorf("maksimal %d baris per import", maxClientImportRows)
+		}
+
+		values := map[string]string{}
+		for i, value := range record {
+			if i < len(columns) && columns[i] != "" {
+				values[columns[i]] = strings.TrimSpace(value)
+			}
+		}
+		records = append(records, values)
+	}
+	return records, nil
+}
+
+// ImportClients menambahkan client dari file CSV (field form "file") dengan
+// kolom nama, email dan no_tlp, serta currency opsional. Email yang sudah ada
+// dilewati, atau diperbarui jika on_duplicate=update. Dengan dry_run=true tidak
+// ada yang disimpan, tetapi laporannya sama seperti import sungguhan.
+func ImportClients(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run harus true atau false"})
+		return
+	}
+	onDuplicate := c.DefaultQuery("on_duplicate", "skip")
+	if onDuplicate != "skip" && onDuplicate != "update" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "on_duplicate harus skip atau update"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file CSV wajib diunggah pada field file"})
+		return
+	}
+	if fileHeader.Size > maxClientImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ukuran file maksimal 5 MB"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file tidak bisa dibaca"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxClientImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file tidak bisa dibaca"})
+		return
+	}
+	records, err := readClientCSV(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := make([]ClientImportRow, 0, len(records))
+	summary := map[string]int{ImportCreated: 0, ImportUpdated: 0, ImportSkipped: 0, ImportRejected: 0}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		seen := map[string]int{} // email -> baris pertama di file
+
+		for i, record := range records {
+			input := ClientRequest{
+				Nama:     record["nama"],
+				Email:    record["email"],
+				NoTlp:    record["no_tlp"],
+				Currency: record["currency"],
+			}
+			result := ClientImportRow{Row: i + 2, Email: input.Email}
+
+			if messages := validateClientRequest(&input); len(messages) > 0 {
+				result.Action = ImportRejected
+				result.Errors = messages
+			} else if first, ok := seen[strings.ToLower(input.Email)]; ok {
+				result.Action = ImportRejected
+				result.Errors = []string{fmt.Sprintf("email sama dengan baris %d", first)}
+			} else {
+				seen[strings.ToLower(input.Email)] = result.Row
+				if err := importClient(tx, userID, input, onDuplicate, dryRun, &result); err != nil {
+					return err
+				}
+			}
+
+			summary[result.Action]++
+			report = append(report, result)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengimpor client"})
+		return
+	}
+
+	message := "Import client selesai"
+	if dryRun {
+		message = "Dry run selesai, belum ada data yang disimpan"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"dry_run": dryRun,
+		"summary": summary,
+		"rows":    report,
+	})
+}
+
+// importClient menyimpan satu baris yang sudah valid dan mengisi hasilnya
+func importClient(tx *gorm.DB, userID uint, input ClientRequest, onDuplicate string, dryRun bool, result *ClientImportRow) error {
+	var existing models.Client
+	err := tx.Where("user_id = ? AND LOWER(email) = LOWER(?)", userID, input.Email).
+		Order("id").Limit(1).Find(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != 0 {
+		result.ClientID = existing.ID
+		if onDuplicate == "skip" {
+			result.Action = ImportSkipped
+			result.Errors = []string{"email sudah terdaftar"}
+			return nil
+		}
+
+		result.Action = ImportUpdated
+		existing.Nama = input.Nama
+		existing.NoTlp = input.NoTlp
+		if input.Currency != "" {
+			existing.Currency = input.Currency
+		}
+		if dryRun {
+			return nil
+		}
+		return tx.Save(&existing).Error
+	}
+
+	result.Action = ImportCreated
+	if dryRun {
+		return nil
+	}
+
+	client := models.Client{
+		UserID:   userID,
+		Nama:     input.Nama,
+		Email:    input.Email,
+		NoTlp:    input.NoTlp,
+		Currency: input.Currency,
+	}
+	if err := tx.Create(&client).Error; err != nil {
+		return err
+	}
+	result.ClientID = client.ID
+	return nil
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+// InviteClientToPortal membuat akun portal untuk client lalu mengirim undangan
+// lewat email. Tautan undangan juga dikembalikan supaya bisa dibagikan manual
+// jika email gagal terkirim.
+func InviteClientToPortal(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var client models.Client
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&client).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client tidak ditemukan"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data user"})
+		return
+	}
+
+	account, inviteURL, err := services.InviteClient(database.DB, client)
+	if err != nil {
+		respondServiceError(c, err, "Gagal membuat undangan portal")
+		return
+	}
+
+	response := gin.H{
+		"message":    "Undangan portal berhasil dikirim",
+		"account":    account,
+		"invite_url": inviteURL,
+	}
+	if err := services.SendClientInvite(client, user, inviteURL); err != nil {
+		response["message"] = "Undangan portal dibuat, tetapi email gagal dikirim"
+		response["email_error"] = err.Error()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeClientPortalAccess mencabut login portal client. Token yang sudah
+// terbit langsung ditolak karena setiap request portal memeriksa akun ini.
+func RevokeClientPortalAccess(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var account models.ClientAccount
+	if err := database.DB.Where("client_id = ? AND user_id = ?", c.Param("id"), userID).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client belum memiliki akun portal"})
+		return
+	}
+
+	now := time.Now()
+	account.DisabledAt = &now
+	account.InviteNonce = ""
+	account.InviteExpiresAt = nil
+	if err := database.DB.Save(&account).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut akses portal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Akses portal client berhasil dicabut", "account": account})
+}
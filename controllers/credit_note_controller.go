@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/pdf"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CreateCreditNoteRequest struct {
+	InvoiceID      uint                    `json:"invoice_id" binding:"required"`
+	IssueDate      string                  `json:"issue_date"` // default hari ini
+	Reason         string                  `json:"reason"`
+	ApplyToInvoice *bool                   `json:"apply_to_invoice"` // default true: langsung kurangi sisa tagihan invoice asli
+	Items          []models.CreditNoteItem `json:"items"`
+}
+
+type CreditNoteAllocationRequest struct {
+	InvoiceID uint         `json:"invoice_id" binding:"required"`
+	Amount    models.Money `json:"amount"` // kosong = sebanyak mungkin
+}
+
+type CreditNoteRefundRequest struct {
+	Amount     models.Money `json:"amount" binding:"required,gt=0"`
+	RefundDate string       `json:"refund_date"` // default hari ini
+	Method     string       `json:"method"`
+	Reference  string       `json:"reference"`
+}
+
+var errCreditNoteNotFound = errors.New("Credit note tidak ditemukan")
+
+// lockCreditNote mengambil credit note milik user dengan kunci baris
+func lockCreditNote(tx *gorm.DB, creditNoteID string, userID uint) (models.CreditNote, error) {
+	var note models.CreditNote
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", creditNoteID, userID).
+		First(&note).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return note, errCreditNoteNotFound
+	}
+	return note, err
+}
+
+// respondCreditNoteError menerjemahkan error credit note menjadi status HTTP
+func respondCreditNoteError(c *gin.Context, err error, message string) {
+	if errors.Is(err, errCreditNoteNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	respondServiceError(c, err, message)
+}
+
+func CreateCreditNote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req CreateCreditNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	if req.IssueDate == "" {
+		req.IssueDate = time.Now().Format("2006-01-02")
+	}
+
+	note := models.CreditNote{
+		UserID:    userID,
+		InvoiceID: req.InvoiceID,
+		IssueDate: req.IssueDate,
+		Reason:    req.Reason,
+		Items:     req.Items,
+	}
+	apply := req.ApplyToInvoice == nil || *req.ApplyToInvoice
+
+	// Nomor credit note dan kredit ke invoice asli dibuat di transaksi yang sama
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.CreateCreditNote(tx, &note, apply, userID)
+	})
+	if err != nil {
+		respondServiceError(c, err, "Gagal menyimpan credit note")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Credit note berhasil dibuat", "credit_note": note})
+}
+
+func GetCreditNotes(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Where("user_id = ?", userID)
+	if clientID := c.Query("client_id"); clientID != "" {
+		query = query.Where("client_id = ?", clientID)
+	}
+	if invoiceID := c.Query("invoice_id"); invoiceID != "" {
+		query = query.Where("invoice_id = ?", invoiceID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var notes []models.CreditNote
+	if err := query.Preload("Items").Order("created_at DESC").Find(&notes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data credit note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"credit_notes": notes})
+}
+
+func GetCreditNoteByID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var note models.CreditNote
+	if err := database.DB.Preload("Items").Preload("Allocations").Preload("Refunds").
+		Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Credit note tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data credit note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// AllocateCreditNote memakai sisa kredit untuk invoice lain milik client yang sama
+func AllocateCreditNote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req CreditNoteAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	var note models.CreditNote
+	var invoice models.Invoice
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if note, err = lockCreditNote(tx, c.Param("id"), userID); err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", req.InvoiceID, userID).
+			First(&invoice).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &services.ValidationError{Message: "invoice tidak ditemukan"}
+			}
+			return err
+		}
+
+		amount := req.Amount
+		if amount == 0 {
+			amount = min(note.Balance, invoice.Balance)
+		}
+		return services.AllocateCreditNote(tx, &note, &invoice, amount, userID)
+	})
+	if err != nil {
+		respondCreditNoteError(c, err, "Gagal memakai kredit")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kredit berhasil dipakai", "credit_note": note, "invoice": invoice})
+}
+
+func RefundCreditNote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req CreditNoteRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	if req.RefundDate == "" {
+		req.RefundDate = time.Now().Format("2006-01-02")
+	}
+
+	var note models.CreditNote
+	refund := models.CreditNoteRefund{
+		Amount:     req.Amount,
+		RefundDate: req.RefundDate,
+		Method:     req.Method,
+		Reference:  req.Reference,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if note, err = lockCreditNote(tx, c.Param("id"), userID); err != nil {
+			return err
+		}
+		return services.RefundCreditNote(tx, &note, &refund)
+	})
+	if err != nil {
+		respondCreditNoteError(c, err, "Gagal menyimpan refund")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Refund berhasil dicatat", "refund": refund, "credit_note": note})
+}
+
+func VoidCreditNote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var note models.CreditNote
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if note, err = lockCreditNote(tx, c.Param("id"), userID); err != nil {
+			return err
+		}
+		return services.VoidCreditNote(tx, &note, userID)
+	})
+	if err != nil {
+		respondCreditNoteError(c, err, "Gagal membatalkan credit note")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Credit note berhasil di-void", "credit_note": note})
+}
+
+func ExportCreditNotePDF(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var note models.CreditNote
+	if err := database.DB.Preload("Items").
+		Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "credit note tidak ditemukan"})
+		return
+	}
+
+	var invoice models.Invoice
+	if err := database.DB.Unscoped().Select("id", "number").First(&invoice, note.InvoiceID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "gagal mengambil data invoice"})
+		return
+	}
+
+	issuer, err := services.LoadIssuer(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "gagal mengambil profil usaha"})
+		return
+	}
+
+	var client models.Client
+	database.DB.Unscoped().Where("id = ?", note.ClientID).Limit(1).Find(&client)
+
+	var buf bytes.Buffer
+	if err := pdf.RenderCreditNote(&buf, note, invoice.Number, issuer, client); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename=credit-note.pdf")
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
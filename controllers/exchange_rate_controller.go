@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/services"
+	"github.com/sholllll662/invoice-backend/utils"
+)
+
+// GetExchangeRates menampilkan kurs setiap mata uang terhadap mata uang dasar user
+func GetExchangeRates(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	date := time.Now()
+	if d := c.Query("date"); d != "" {
+		parsed, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format date harus YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	setting, err := services.LoadUserSetting(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	rates := gin.H{}
+	for _, code := range utils.ExchangeRateCurrencies() {
+		if rate, err := utils.ExchangeRate(code, setting.BaseCurrency, date); err == nil {
+			rates[code] = rate
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"base_currency": setting.BaseCurrency,
+		"date":          date.Format("2006-01-02"),
+		"rates":         rates,
+	})
+}
@@ -1,26 +1,38 @@
 package controller
 
 import (
-	"fmt"
+	"bytes"
+	"errors"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/dustin/go-humanize"
 	"github.com/gin-gonic/gin"
-	"github.com/jung-kurt/gofpdf"
 	"github.com/sholllll662/invoice-backend/database"
 	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInvoiceNotEditable = errors.New("invoice tidak bisa diubah")
+	errInvoiceHasCredits  = errors.New("Invoice yang memiliki credit note tidak bisa diubah, buat credit note baru untuk koreksi")
 )
 
 type CreateInvoiceRequest struct {
-	ClientID  uint                 `json:"client_id"`
-	IssueDate string               `json:"issue_date"`
-	DueDate   string               `json:"due_date"`
-	Status    string               `json:"status"`
-	Note      string               `json:"note"`
-	Items     []models.InvoiceItem `json:"items"`
+	ClientID      uint                 `json:"client_id"`
+	IssueDate     string               `json:"issue_date"`
+	DueDate       string               `json:"due_date"`
+	Note          string               `json:"note"`
+	DiscountType  string               `json:"discount_type"` // "percent" atau "fixed"
+	DiscountValue models.Money         `json:"discount_value"`
+	Currency      string               `json:"currency"`      // kosong = mata uang client / user
+	ExchangeRate  float64              `json:"exchange_rate"` // opsional, isi manual jika kurs tidak ada di file
+	PDFTemplate   string               `json:"pdf_template"`  // kosong = template default user
+	Items         []models.InvoiceItem `json:"items"`
 }
 
 func CreateInvoice(c *gin.Context) {
@@ -40,33 +52,45 @@ func CreateInvoice(c *gin.Context) {
 	}
 	userID := userIDInterface.(uint)
 
-	// Hitung total amount dari item-item
-	var totalAmount float64
-	for i := range req.Items {
-		req.Items[i].TotalPrice = float64(req.Items[i].Quantity) * req.Items[i].UnitPrice
-		totalAmount += req.Items[i].TotalPrice
-	}
-
 	// Buat Invoice
 	invoice := models.Invoice{
-		UserID:    userID,
-		ClientID:  req.ClientID,
-		IssueDate: req.IssueDate,
-		DueDate:   req.DueDate,
-		Amount:    totalAmount,
-		Status:    req.Status,
-		Note:      req.Note,
-		Items:     req.Items,
-	}
-
-	if err := database.DB.Create(&invoice).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan invoice"})
+		UserID:        userID,
+		ClientID:      req.ClientID,
+		IssueDate:     req.IssueDate,
+		DueDate:       req.DueDate,
+		Note:          req.Note,
+		Items:         req.Items,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		PDFTemplate:   req.PDFTemplate,
+	}
+
+	// Nomor dialokasikan di transaksi yang sama agar tidak bolong jika gagal
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.CreateInvoice(tx, &invoice, services.InvoiceOptions{
+			Currency:     req.Currency,
+			ExchangeRate: req.ExchangeRate,
+			ActorID:      userID,
+		})
+	})
+	if err != nil {
+		respondServiceError(c, err, "Gagal menyimpan invoice")
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Invoice berhasil dibuat", "invoice": invoice})
 }
 
+// respondServiceError mengirim 400 untuk ValidationError dan 500 untuk error lain
+func respondServiceError(c *gin.Context, err error, message string) {
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
 func GetInvoices(c *gin.Context) {
 	// Ambil user ID dari context
 	userIDInterface, exists := c.Get("userID")
@@ -76,27 +100,153 @@ func GetInvoices(c *gin.Context) {
 	}
 	userID := userIDInterface.(uint)
 
-	// Ambil query parameters
-	status := c.Query("status")
-	clientID := c.Query("client_id")
-
 	var invoices []models.Invoice
-	query := database.DB.Preload("Items").Where("user_id = ?", userID)
-
-	if status != "" {
-		query = query.Where("status = ?", status)
+	query, ok := invoiceFilterQuery(c, userID)
+	if !ok {
+		return
+	}
+	list, ok := parseListQuery(c, invoiceSortFields, "invoices.created_at DESC", "invoices.id")
+	if !ok {
+		return
 	}
 
-	if clientID != "" {
-		query = query.Where("client_id = ?", clientID)
+	// Query filter dipakai tiga kali: jumlah baris, satu halaman invoice dan ringkasan total
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung invoice"})
+		return
 	}
 
-	if err := query.Order("created_at DESC").Find(&invoices).Error; err != nil {
+	err := list.Apply(query).
+		Joins("LEFT JOIN clients ON clients.id = invoices.client_id").
+		Select("invoices.*").
+		Preload("Items").
+		Find(&invoices).Error
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"invoices": invoices})
+	setting, err := services.LoadUserSetting(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	today := services.UserToday(setting, time.Now())
+	for i := range invoices {
+		invoices[i].SetDaysOverdue(today)
+	}
+
+	// Ringkasan dikonversi ke mata uang dasar user memakai kurs saat terbit
+	var summary struct {
+		TotalAmount  models.Money `json:"total_amount"`
+		TotalBalance models.Money `json:"total_balance"`
+	}
+	if err := query.Model(&models.Invoice{}).
+		Where("base_currency = ? AND status NOT IN ?", setting.BaseCurrency, []string{models.StatusVoid, models.StatusCancelled}).
+		Select(`COALESCE(SUM(base_amount), 0)::bigint AS total_amount,
+			COALESCE(SUM(ROUND(balance * exchange_rate)), 0)::bigint AS total_balance`).
+		Scan(&summary).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung ringkasan invoice"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invoices":   invoices,
+		"pagination": list.Pagination(total),
+		"summary": gin.H{
+			"base_currency": setting.BaseCurrency,
+			"total_amount":  summary.TotalAmount,
+			"total_balance": summary.TotalBalance,
+		},
+	})
+}
+
+// invoiceSortFields adalah field yang bisa dipakai di ?sort= daftar invoice
+var invoiceSortFields = map[string]string{
+	"number":      "invoices.number",
+	"issue_date":  "invoices.issue_date",
+	"due_date":    "invoices.due_date",
+	"amount":      "invoices.amount",
+	"balance":     "invoices.balance",
+	"status":      "invoices.status",
+	"client_name": "LOWER(clients.nama)",
+	"created_at":  "invoices.created_at",
+}
+
+// invoiceDateFilters memetakan query parameter rentang tanggal ke kolom invoice
+var invoiceDateFilters = []struct {
+	param string
+	cond  string
+}{
+	{"issue_from", "invoices.issue_date >= ?"},
+	{"issue_to", "invoices.issue_date <= ?"},
+	{"due_from", "invoices.due_date >= ?"},
+	{"due_to", "invoices.due_date <= ?"},
+}
+
+// invoiceFilterQuery membangun query invoice milik user dari filter status
+// (boleh lebih dari satu), client_id, search, overdue, rentang tanggal dan
+// rentang total. Dipakai bersama oleh daftar
+// invoice dan export. Kolom ditulis lengkap dengan nama tabel supaya aman
+// di-join dengan tabel lain.
+func invoiceFilterQuery(c *gin.Context, userID uint) (*gorm.DB, bool) {
+	query := database.DB.Model(&models.Invoice{}).Where("invoices.user_id = ?", userID)
+
+	if statuses := queryList(c, "status"); len(statuses) > 0 {
+		query = query.Where("invoices.status IN ?", statuses)
+	}
+
+	if clientID := c.Query("client_id"); clientID != "" {
+		query = query.Where("invoices.client_id = ?", clientID)
+	}
+
+	if search := c.Query("search"); search != "" {
+		query = query.Where("LOWER(invoices.number) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
+	if overdue := c.Query("overdue"); overdue != "" {
+		isOverdue, err := strconv.ParseBool(overdue)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "overdue harus true atau false"})
+			return nil, false
+		}
+		query = query.Where("invoices.is_overdue = ?", isOverdue)
+	}
+
+	for _, filter := range invoiceDateFilters {
+		value := c.Query(filter.param)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format " + filter.param + " harus YYYY-MM-DD"})
+			return nil, false
+		}
+		query = query.Where(filter.cond, value)
+	}
+
+	// Rentang total dalam mata uang invoice masing-masing
+	for param, cond := range map[string]string{
+		"amount_min": "invoices.amount >= ?",
+		"amount_max": "invoices.amount <= ?",
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		amount, err := models.ParseMoney(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " harus berupa angka"})
+			return nil, false
+		}
+		query = query.Where(cond, amount)
+	}
+
+	return query, true
 }
 
 func GetInvoiceByID(c *gin.Context) {
@@ -112,7 +262,14 @@ func GetInvoiceByID(c *gin.Context) {
 	invoiceID := c.Param("id")
 
 	var invoice models.Invoice
-	err := database.DB.Preload("Items").
+	err := database.DB.Preload("Items").Preload("Payments").
+		Preload("History", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Reminders", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Credits").
 		Where("id = ? AND user_id = ?", invoiceID, userID).
 		First(&invoice).Error
 
@@ -125,6 +282,13 @@ func GetInvoiceByID(c *gin.Context) {
 		return
 	}
 
+	setting, err := services.LoadUserSetting(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+	invoice.SetDaysOverdue(services.UserToday(setting, time.Now()))
+
 	c.JSON(http.StatusOK, invoice)
 }
 
@@ -140,20 +304,6 @@ func UpdateInvoiceByID(c *gin.Context) {
 	// Ambil invoice ID dari parameter URL
 	invoiceID := c.Param("id")
 
-	var existingInvoice models.Invoice
-	err := database.DB.Preload("Items").
-		Where("id = ? AND user_id = ?", invoiceID, userID).
-		First(&existingInvoice).Error
-
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
-		return
-	}
-
 	// Ambil data dari request
 	var req CreateInvoiceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -161,30 +311,69 @@ func UpdateInvoiceByID(c *gin.Context) {
 		return
 	}
 
-	// Hitung ulang total amount dan total harga per item
-	var totalAmount float64
-	for i := range req.Items {
-		req.Items[i].TotalPrice = float64(req.Items[i].Quantity) * req.Items[i].UnitPrice
-		totalAmount += req.Items[i].TotalPrice
-	}
+	var existingInvoice models.Invoice
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris invoice supaya pembayaran atau perubahan bersamaan menunggu
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", invoiceID, userID).
+			First(&existingInvoice).Error; err != nil {
+			return err
+		}
 
-	// Hapus item lama
-	if err := database.DB.Where("invoice_id = ?", invoiceID).Delete(&models.InvoiceItem{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus item lama"})
-		return
-	}
+		// Hanya invoice Draft atau Terkirim yang boleh diubah
+		if !models.IsEditable(existingInvoice.Status) {
+			return errInvoiceNotEditable
+		}
+
+		// Koreksi invoice yang sudah dikredit dilakukan lewat credit note baru
+		hasCredits, err := services.InvoiceHasCreditNotes(tx, existingInvoice.ID)
+		if err != nil {
+			return err
+		}
+		if hasCredits {
+			return errInvoiceHasCredits
+		}
+
+		// Update invoice utama
+		existingInvoice.ClientID = req.ClientID
+		existingInvoice.Note = req.Note
+		existingInvoice.IssueDate = req.IssueDate
+		existingInvoice.DueDate = req.DueDate
+		existingInvoice.Items = req.Items
+		existingInvoice.DiscountType = req.DiscountType
+		existingInvoice.DiscountValue = req.DiscountValue
+		existingInvoice.PDFTemplate = req.PDFTemplate
+
+		// Salin ulang pajak, tentukan kurs lalu hitung ulang diskon, subtotal, pajak dan total
+		if err := services.PrepareInvoice(tx, &existingInvoice, services.InvoiceOptions{
+			Currency:     req.Currency,
+			ExchangeRate: req.ExchangeRate,
+		}); err != nil {
+			return err
+		}
 
-	// Update invoice utama
-	existingInvoice.ClientID = req.ClientID
-	existingInvoice.Status = req.Status
-	existingInvoice.Note = req.Note
-	existingInvoice.IssueDate = req.IssueDate
-	existingInvoice.DueDate = req.DueDate
-	existingInvoice.Amount = totalAmount
-	existingInvoice.Items = req.Items
+		// Hapus item lama
+		if err := tx.Where("invoice_id = ?", existingInvoice.ID).Delete(&models.InvoiceItem{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Save(&existingInvoice).Error
+	})
 
-	if err := database.DB.Save(&existingInvoice).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui invoice"})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+			return
+		}
+		if errors.Is(err, errInvoiceNotEditable) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Invoice dengan status " + existingInvoice.Status + " tidak bisa diubah"})
+			return
+		}
+		if errors.Is(err, errInvoiceHasCredits) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		respondServiceError(c, err, "Gagal memperbarui invoice")
 		return
 	}
 
@@ -215,12 +404,29 @@ func DeleteInvoiceByID(c *gin.Context) {
 		return
 	}
 
+	// Invoice yang sudah punya credit note harus tetap ada sebagai jejak koreksi
+	hasCredits, err := services.InvoiceHasCreditNotes(database.DB, invoice.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa credit note invoice"})
+		return
+	}
+	if hasCredits {
+		c.JSON(http.StatusConflict, gin.H{"error": "Invoice yang memiliki credit note tidak bisa dihapus"})
+		return
+	}
+
 	// Hapus semua item terkait
 	if err := database.DB.Where("invoice_id = ?", invoiceID).Delete(&models.InvoiceItem{}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus item invoice"})
 		return
 	}
 
+	// Hapus semua pembayaran terkait
+	if err := database.DB.Where("invoice_id = ?", invoiceID).Delete(&models.InvoicePayment{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus pembayaran invoice"})
+		return
+	}
+
 	// Hapus invoice utama
 	if err := database.DB.Delete(&invoice).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus invoice"})
@@ -230,10 +436,6 @@ func DeleteInvoiceByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Invoice berhasil dihapus"})
 }
 
-func formatRupiah(amount float64) string {
-	return fmt.Sprintf("Rp %s", humanize.Commaf(amount))
-}
-
 func ExportInvoicePDF(c *gin.Context) {
 	invoiceID := c.Param("id")
 
@@ -253,144 +455,14 @@ func ExportInvoicePDF(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "gagal mengambil data user"})
-		return
-	}
-
-	// // Generate PDF
-	// pdf := gofpdf.New("P", "mm", "A4", "")
-	// pdf.AddPage()
-	// pdf.SetFont("Arial", "B", 30)
-	// pdf.Cell(40, 10, "INVOICE")
-
-	// // Info invoice
-	// pdf.SetFont("Arial", "", 12)
-	// pdf.Ln(10)
-	// issueDate, _ := time.Parse("2006-01-02", invoice.IssueDate)
-	// pdf.Cell(100, 10, "Tanggal: "+issueDate.Format("02 Jan 2006"))
-	// pdf.Ln(6)
-	// pdf.Cell(100, 10, "Status: "+invoice.Status)
-	// pdf.Ln(6)
-
-	// // Tabel item
-	// pdf.Ln(10)
-	// pdf.SetFont("Arial", "B", 12)
-	// pdf.CellFormat(80, 10, "Item", "1", 0, "", false, 0, "")
-	// pdf.CellFormat(30, 10, "Qty", "1", 0, "", false, 0, "")
-	// pdf.CellFormat(40, 10, "Harga", "1", 0, "", false, 0, "")
-	// pdf.CellFormat(40, 10, "Total", "1", 1, "", false, 0, "")
-
-	// pdf.SetFont("Arial", "", 12)
-	// for _, item := range invoice.Items {
-	// 	pdf.CellFormat(80, 10, item.ItemName, "1", 0, "", false, 0, "")
-	// 	pdf.CellFormat(30, 10, strconv.Itoa(item.Quantity), "1", 0, "", false, 0, "")
-	// 	pdf.CellFormat(40, 10, formatRupiah(item.UnitPrice), "1", 0, "", false, 0, "")
-	// 	pdf.CellFormat(40, 10, formatRupiah(item.TotalPrice), "1", 1, "", false, 0, "")
-	// }
-
-	// // Total
-	// pdf.Ln(6)
-	// pdf.SetFont("Arial", "B", 12)
-	// pdf.Cell(150, 10, "TOTAL:")
-	// pdf.Cell(40, 10, formatRupiah(invoice.Amount))
-
-	// // Output PDF
-	// err := pdf.Output(c.Writer)
-	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
-	// 	return
-	// }
-	// c.Header("Content-Disposition", "inline; filename=invoice.pdf")
-	// c.Header("Content-Type", "application/pdf")
-
-	// Buat PDF
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Judul
-	pdf.SetFont("Arial", "B", 20)
-	pdf.Cell(0, 10, "INVOICE")
-	pdf.Ln(12)
-
-	// Informasi KEPADA dan TANGGAL
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(30, 6, "KEPADA :")
-	pdf.Cell(90, 6, "")
-	pdf.Cell(30, 6, "TANGGAL :")
-	pdf.Ln(6)
-
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(60, 6, user.Name) // Ganti sesuai data
-	pdf.Cell(60, 6, "")
-	issueDate, _ := time.Parse("2006-01-02", invoice.IssueDate)
-	pdf.Cell(60, 6, issueDate.Format("Monday, 02 January 2006"))
-	pdf.Ln(6)
-
-	pdf.Cell(60, 6, user.Email) // Ganti sesuai data
-	pdf.Ln(10)
-
-	// // Nomor invoice
-	// pdf.SetFont("Arial", "B", 12)
-	// pdf.Cell(40, 6, "NO INVOICE :")
-	// pdf.SetFont("Arial", "", 12)
-	// pdf.Cell(40, 6, invoice.ID) // Ganti sesuai data
-	// pdf.Ln(10)
-
-	// Header tabel item
-	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(80, 10, "KETERANGAN", "0", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "HARGA", "0", 0, "C", false, 0, "")
-	pdf.CellFormat(30, 10, "JML", "0", 0, "C", false, 0, "")
-	pdf.CellFormat(40, 10, "TOTAL", "0", 1, "C", false, 0, "")
-
-	pdf.SetFont("Arial", "", 12)
-	for i, item := range invoice.Items {
-		isLast := i == len(invoice.Items)-1
-
-		border := "B"
-		fill := true
-
-		if isLast {
-			border = "" // Tidak ada garis untuk baris terakhir
-			fill = true // Opsional: tidak ada background
-		}
-
-		pdf.SetFillColor(230, 230, 230)
-		pdf.CellFormat(80, 10, item.ItemName, border, 0, "", fill, 0, "")
-		pdf.CellFormat(40, 10, formatRupiah(item.UnitPrice), border, 0, "C", fill, 0, "")
-		pdf.CellFormat(30, 10, strconv.Itoa(item.Quantity), border, 0, "C", fill, 0, "")
-		pdf.CellFormat(40, 10, formatRupiah(item.TotalPrice), border, 1, "C", fill, 0, "")
-	}
-
-	// Subtotal
-	pdf.Ln(4)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(80, 10, "", "0 ", 0, "", false, 0, "")
-	pdf.CellFormat(40, 10, "", "0", 0, "", false, 0, "")
-	pdf.CellFormat(30, 10, "Sub Total", "0", 0, "C", false, 0, "")
-	pdf.CellFormat(40, 10, formatRupiah(invoice.Amount), "0", 1, "C", false, 0, "")
-	// pdf.Ln(4)
-	// pdf.SetFont("Arial", "B", 12)
-	// pdf.Cell(120, 10, "")
-	// pdf.Cell(30, 10, "SUB TOTAL :")
-	// pdf.Cell(40, 10, formatRupiah(invoice.Amount))
-
-	// Terima kasih
-	pdf.Ln(20)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(0, 10, "TERIMAKASIH ATAS")
-	pdf.Ln(6)
-	pdf.Cell(0, 10, "PEMBELIAN ANDA")
-
-	// Output PDF
-	err := pdf.Output(c.Writer)
-	if err != nil {
+	// Buat PDF di buffer dulu supaya error masih bisa dibalas sebagai JSON
+	var buf bytes.Buffer
+	if err := services.RenderInvoicePDF(database.DB, &buf, invoice); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
 		return
 	}
-	c.Header("Content-Disposition", "inline; filename=invoice.pdf")
-	c.Header("Content-Type", "application/pdf")
 
+	// Nama file mengikuti nomor invoice, sama seperti lampiran email
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": services.InvoicePDFFilename(&invoice)}))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
 }
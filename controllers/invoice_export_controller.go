@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+// invoiceExportRow adalah satu baris export per invoice
+type invoiceExportRow struct {
+	Number         string
+	Status         string
+	ClientName     string
+	ClientEmail    string
+	IssueDate      string
+	DueDate        string
+	Currency       string
+	ExchangeRate   float64
+	Subtotal       models.Money
+	DiscountAmount models.Money
+	TaxTotal       models.Money
+	Amount         models.Money
+	AmountPaid     models.Money
+	CreditedAmount models.Money
+	Balance        models.Money
+	BaseCurrency   string
+	BaseAmount     models.Money
+	IsOverdue      bool
+	Note           string
+}
+
+// invoiceItemExportRow adalah satu baris export per item invoice
+type invoiceItemExportRow struct {
+	Number         string
+	Status         string
+	ClientName     string
+	IssueDate      string
+	DueDate        string
+	Currency       string
+	ItemName       string
+	CatalogItemID  *uint
+	Quantity       int
+	UnitPrice      models.Money
+	DiscountAmount models.Money
+	TotalPrice     models.Money
+	TaxAmount      models.Money
+	Taxes          string
+}
+
+var invoiceExportHeader = []any{
+	"No Invoice", "Status", "Client", "Email Client", "Tanggal Terbit", "Jatuh Tempo",
+	"Mata Uang", "Kurs", "Subtotal", "Diskon", "Pajak", "Total", "Dibayar", "Dikredit",
+	"Sisa Tagihan", "Mata Uang Dasar", "Total (Mata Uang Dasar)", "Overdue", "Catatan",
+}
+
+var invoiceItemExportHeader = []any{
+	"No Invoice", "Status", "Client", "Tanggal Terbit", "Jatuh Tempo", "Mata Uang",
+	"Item", "ID Katalog", "Jumlah", "Harga Satuan", "Diskon", "Total", "Pajak", "Rincian Pajak",
+}
+
+// exportMoney menulis nominal sebagai angka desimal, bukan teks
+func exportMoney(m models.Money) utils.Number {
+	return utils.Number(m.String())
+}
+
+// ExportInvoices mengalirkan invoice yang cocok dengan filter GetInvoices sebagai
+// CSV atau XLSX. ?rows=items menghasilkan satu baris per item invoice.
+// Baris dibaca satu per satu dari database sehingga export besar tidak
+// ditampung di memori.
+func ExportInvoices(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format harus csv atau xlsx"})
+		return
+	}
+	rowsMode := c.DefaultQuery("rows", "invoices")
+	if rowsMode != "invoices" && rowsMode != "items" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rows harus invoices atau items"})
+		return
+	}
+
+	query, ok := invoiceFilterQuery(c, userID)
+	if !ok {
+		return
+	}
+	query = query.Joins("LEFT JOIN clients ON clients.id = invoices.client_id")
+
+	if rowsMode == "items" {
+		query = query.Joins("JOIN invoice_items ON invoice_items.invoice_id = invoices.id").
+			Select(`invoices.number, invoices.status, COALESCE(clients.nama, '') AS client_name,
+				invoices.issue_date, invoices.due_date, invoices.currency,
+				invoice_items.item_name, invoice_items.catalog_item_id, invoice_items.quantity,
+				invoice_items.unit_price, invoice_items.discount_amount, invoice_items.total_price,
+				invoice_items.tax_amount,
+				COALESCE((SELECT string_agg(CONCAT(t->>'name', ' ', t->>'rate', '%'), ', ')
+					FROM jsonb_array_elements(CASE WHEN jsonb_typeof(invoice_items.taxes) = 'array'
+						THEN invoice_items.taxes ELSE '[]'::jsonb END) AS t), '') AS taxes`).
+			Order("invoices.issue_date, invoices.id, invoice_items.id")
+	} else {
+		query = query.
+			Select(`invoices.number, invoices.status, COALESCE(clients.nama, '') AS client_name,
+				COALESCE(clients.email, '') AS client_email, invoices.issue_date, invoices.due_date,
+				invoices.currency, invoices.exchange_rate, invoices.subtotal, invoices.discount_amount,
+				invoices.tax_total, invoices.amount, invoices.amount_paid, invoices.credited_amount,
+				invoices.balance, invoices.base_currency, invoices.base_amount, invoices.is_overdue,
+				invoices.note`).
+			Order("invoices.issue_date, invoices.id")
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("invoices-%s-%s.%s", rowsMode, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	var writer utils.RowWriter
+	if format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		writer, err = utils.NewXLSXRowWriter(c.Writer, "Invoices")
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		writer = utils.NewCSVRowWriter(c.Writer)
+	}
+	if err == nil {
+		err = writeInvoiceExport(database.DB, rows, writer, rowsMode)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+
+	// Header sudah terkirim, error hanya bisa dicatat dan file terpotong
+	if err != nil {
+		log.Printf("❌ export invoice user #%d gagal: %v", userID, err)
+		c.Abort()
+	}
+}
+
+// writeInvoiceExport menulis judul kolom lalu setiap baris hasil query
+func writeInvoiceExport(db *gorm.DB, sqlRows *sql.Rows, writer utils.RowWriter, rowsMode string) error {
+	if rowsMode == "items" {
+		if err := writer.WriteRow(invoiceItemExportHeader...); err != nil {
+			return err
+		}
+		for sqlRows.Next() {
+			var row invoiceItemExportRow
+			if err := db.ScanRows(sqlRows, &row); err != nil {
+				return err
+			}
+			catalogID := ""
+			if row.CatalogItemID != nil {
+				catalogID = fmt.Sprint(*row.CatalogItemID)
+			}
+			if err := writer.WriteRow(row.Number, row.Status, row.ClientName, row.IssueDate, row.DueDate,
+				row.Currency, row.ItemName, catalogID, row.Quantity, exportMoney(row.UnitPrice),
+				exportMoney(row.DiscountAmount), exportMoney(row.TotalPrice), exportMoney(row.TaxAmount), row.Taxes); err != nil {
+				return err
+			}
+		}
+		return sqlRows.Err()
+	}
+
+	if err := writer.WriteRow(invoiceExportHeader...); err != nil {
+		return err
+	}
+	for sqlRows.Next() {
+		var row invoiceExportRow
+		if err := db.ScanRows(sqlRows, &row); err != nil {
+			return err
+		}
+		if err := writer.WriteRow(row.Number, row.Status, row.ClientName, row.ClientEmail, row.IssueDate,
+			row.DueDate, row.Currency, row.ExchangeRate, exportMoney(row.Subtotal), exportMoney(row.DiscountAmount),
+			exportMoney(row.TaxTotal), exportMoney(row.Amount), exportMoney(row.AmountPaid), exportMoney(row.CreditedAmount),
+			exportMoney(row.Balance), row.BaseCurrency, exportMoney(row.BaseAmount), row.IsOverdue, row.Note); err != nil {
+			return err
+		}
+	}
+	return sqlRows.Err()
+}
@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceTransitionRequest struct {
+	Note string `json:"note"`
+}
+
+// changeInvoiceStatus menjalankan satu perpindahan status lewat endpoint khusus
+func changeInvoiceStatus(c *gin.Context, to string, message string) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoiceID := c.Param("id")
+
+	// Body opsional, hanya berisi catatan
+	var req InvoiceTransitionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+			return
+		}
+	}
+
+	invoice, err := transitionInvoiceByID(invoiceID, userID, to, req.Note)
+	if err != nil {
+		respondTransitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message, "invoice": invoice})
+}
+
+// transitionInvoiceByID mengunci baris invoice lalu memindahkan statusnya
+func transitionInvoiceByID(invoiceID string, userID uint, to string, note string) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", invoiceID, userID).
+			First(&invoice).Error; err != nil {
+			return err
+		}
+
+		return services.TransitionInvoice(tx, &invoice, to, userID, note)
+	})
+	return invoice, err
+}
+
+func respondTransitionError(c *gin.Context, err error) {
+	var transitionErr *services.TransitionError
+	if errors.As(err, &transitionErr) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   transitionErr.Error(),
+			"status":  transitionErr.From,
+			"allowed": transitionErr.Allowed,
+		})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah status invoice"})
+}
+
+type SendInvoiceRequest struct {
+	Note      string `json:"note"`
+	SkipEmail bool   `json:"skip_email"` // hanya tandai terkirim, misalnya invoice dikirim manual
+}
+
+// SendInvoice mengirim PDF invoice ke email client lalu menandainya terkirim.
+// Invoice yang sudah terkirim boleh dikirim ulang tanpa mengubah status.
+// Perpindahan status diperiksa sebelum email dikirim.
+func SendInvoice(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoiceID := c.Param("id")
+
+	var req SendInvoiceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+			return
+		}
+	}
+
+	if req.SkipEmail {
+		invoice, err := transitionInvoiceByID(invoiceID, userID, models.StatusSent, req.Note)
+		if err != nil {
+			respondTransitionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Invoice ditandai terkirim", "invoice": invoice})
+		return
+	}
+
+	// Invoice dikunci selama email dikirim supaya tidak di-void atau dibatalkan
+	// di antara pengiriman dan perubahan status. Log email ditulis lewat
+	// database.DB sehingga tetap tersimpan walau transaksinya gagal.
+	var invoice models.Invoice
+	var emailLog models.InvoiceEmailLog
+	var emailErr error
+	emailSent := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", invoiceID, userID).
+			First(&invoice).Error; err != nil {
+			return err
+		}
+
+		wasDraft := invoice.Status == models.StatusDraft
+		if wasDraft && !models.CanTransition(invoice.Status, models.StatusSent) {
+			return &services.TransitionError{From: invoice.Status, To: models.StatusSent, Allowed: models.AllowedTransitions(invoice.Status)}
+		}
+
+		emailLog, emailErr = services.EmailInvoice(database.DB, &invoice, userID)
+		if emailErr != nil {
+			return nil
+		}
+		emailSent = true
+
+		if !wasDraft {
+			return nil
+		}
+		note := req.Note
+		if note == "" {
+			note = "Dikirim ke " + emailLog.Recipient
+		}
+		return services.TransitionInvoice(tx, &invoice, models.StatusSent, userID, note)
+	})
+
+	// Email sudah sampai ke client, jadi kegagalan mengubah status dilaporkan terpisah
+	if err != nil && emailSent {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invoice sudah terkirim ke email client, tetapi status invoice gagal diperbarui",
+			"email": emailLog,
+		})
+		return
+	}
+	if err != nil {
+		respondTransitionError(c, err)
+		return
+	}
+
+	if emailErr != nil {
+		var deliveryErr *services.DeliveryError
+		switch {
+		case errors.Is(emailErr, utils.ErrMailerNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": emailErr.Error(), "email": emailLog})
+		case errors.As(emailErr, &deliveryErr):
+			c.JSON(http.StatusBadGateway, gin.H{"error": emailErr.Error(), "email": emailLog})
+		default:
+			respondServiceError(c, emailErr, "Gagal mengirim invoice")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invoice berhasil dikirim", "invoice": invoice, "email": emailLog})
+}
+
+func GetInvoiceEmails(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	var invoice models.Invoice
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
+		return
+	}
+
+	var emails []models.InvoiceEmailLog
+	if err := database.DB.Where("invoice_id = ?", invoice.ID).Order("created_at DESC, id DESC").Find(&emails).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil log email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"emails": emails})
+}
+
+func VoidInvoice(c *gin.Context) {
+	changeInvoiceStatus(c, models.StatusVoid, "Invoice berhasil di-void")
+}
+
+func CancelInvoice(c *gin.Context) {
+	changeInvoiceStatus(c, models.StatusCancelled, "Invoice berhasil dibatalkan")
+}
+
+func GetInvoiceHistory(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoiceID := c.Param("id")
+
+	var invoice models.Invoice
+	err := database.DB.Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).
+		Where("id = ? AND user_id = ?", invoiceID, userID).
+		First(&invoice).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  invoice.Status,
+		"allowed": models.AllowedTransitions(invoice.Status),
+		"history": invoice.History,
+	})
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+)
+
+type InvoiceTemplateRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	Layout       string   `json:"layout" binding:"required"`
+	AccentColor  string   `json:"accent_color"`
+	LogoPosition string   `json:"logo_position"`
+	Columns      []string `json:"columns" binding:"required"`
+	FooterText   string   `json:"footer_text" binding:"max=2000"`
+	TermsText    string   `json:"terms_text" binding:"max=5000"`
+}
+
+// apply menyalin isi request ke template lalu memvalidasinya
+func (req InvoiceTemplateRequest) apply(template *models.InvoiceTemplate) error {
+	template.Name = strings.TrimSpace(req.Name)
+	template.Layout = req.Layout
+	template.AccentColor = req.AccentColor
+	template.LogoPosition = req.LogoPosition
+	template.Columns = req.Columns
+	template.FooterText = strings.TrimSpace(req.FooterText)
+	template.TermsText = strings.TrimSpace(req.TermsText)
+	return template.Validate()
+}
+
+// findInvoiceTemplate mengambil template buatan user, template bawaan tidak bisa diubah
+func findInvoiceTemplate(c *gin.Context, userID uint) (models.InvoiceTemplate, bool) {
+	var template models.InvoiceTemplate
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template tidak ditemukan"})
+			return template, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil template"})
+		return template, false
+	}
+	template.SetKey()
+	return template, true
+}
+
+// GetInvoiceTemplates menampilkan template bawaan diikuti template buatan user
+func GetInvoiceTemplates(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var custom []models.InvoiceTemplate
+	if err := database.DB.Where("user_id = ?", userID).Order("name ASC").Find(&custom).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data template"})
+		return
+	}
+
+	templates := models.BuiltinInvoiceTemplates()
+	for _, template := range custom {
+		template.SetKey()
+		templates = append(templates, template)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+func CreateInvoiceTemplate(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req InvoiceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	template := models.InvoiceTemplate{UserID: userID}
+	if err := req.apply(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan template"})
+		return
+	}
+	template.SetKey()
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Template berhasil dibuat", "template": template})
+}
+
+func GetInvoiceTemplateByID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	// Template bawaan juga bisa dibaca lewat key-nya
+	if template, ok := models.BuiltinInvoiceTemplate(c.Param("id")); ok {
+		c.JSON(http.StatusOK, template)
+		return
+	}
+
+	template, ok := findInvoiceTemplate(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func UpdateInvoiceTemplate(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	template, ok := findInvoiceTemplate(c, userID)
+	if !ok {
+		return
+	}
+
+	var req InvoiceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	if err := req.apply(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// PDF selalu dibuat ulang, jadi perubahan ikut berlaku untuk invoice lama yang memakainya
+	if err := database.DB.Save(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template berhasil diupdate", "template": template})
+}
+
+func DeleteInvoiceTemplate(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	template, ok := findInvoiceTemplate(c, userID)
+	if !ok {
+		return
+	}
+
+	// Invoice dan pengaturan yang memakai template ini kembali ke template default
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&template).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Invoice{}).
+			Where("user_id = ? AND pdf_template = ?", userID, template.Key).
+			Update("pdf_template", "").Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.UserSetting{}).
+			Where("user_id = ? AND invoice_template = ?", userID, template.Key).
+			Update("invoice_template", "").Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template berhasil dihapus"})
+}
+
+// renderInvoicePreview membalas PDF invoice contoh dengan template yang diberikan
+func renderInvoicePreview(c *gin.Context, userID uint, template models.InvoiceTemplate) {
+	var buf bytes.Buffer
+	if err := services.RenderInvoicePreview(database.DB, &buf, userID, template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", "inline; filename=preview.pdf")
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// PreviewInvoiceTemplate merender invoice contoh dengan template bawaan atau
+// template tersimpan, :id boleh berupa key seperti "modern"
+func PreviewInvoiceTemplate(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	template, err := services.FindInvoiceTemplate(database.DB, userID, c.Param("id"))
+	if err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil template"})
+		return
+	}
+
+	renderInvoicePreview(c, userID, template)
+}
+
+// PreviewInvoiceTemplateDraft merender invoice contoh dengan template yang
+// belum disimpan, body sama seperti saat membuat template
+func PreviewInvoiceTemplateDraft(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req InvoiceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	var template models.InvoiceTemplate
+	if err := req.apply(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	renderInvoicePreview(c, userID, template)
+}
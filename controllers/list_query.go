@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas ukuran halaman daftar
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// listQuery berisi halaman dan urutan dari query parameter daftar yang sama
+// untuk semua endpoint: ?page=1&page_size=20&sort=-due_date,amount
+// Tanda "-" di depan field berarti urut menurun.
+type listQuery struct {
+	Page     int
+	PageSize int
+	Order    string
+}
+
+// parseListQuery membaca page, page_size dan sort. sortFields memetakan nama
+// field yang boleh dipakai ke kolom SQL, idColumn dipakai sebagai urutan
+// terakhir supaya hasil per halaman stabil.
+func parseListQuery(c *gin.Context, sortFields map[string]string, defaultSort, idColumn string) (listQuery, bool) {
+	list := listQuery{Page: 1, PageSize: defaultPageSize}
+
+	if page := c.Query("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page harus angka mulai dari 1"})
+			return list, false
+		}
+		list.Page = n
+	}
+
+	if size := c.Query("page_size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 1 || n > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size harus angka 1 sampai " + strconv.Itoa(maxPageSize)})
+			return list, false
+		}
+		list.PageSize = n
+	}
+
+	var order []string
+	for _, field := range queryList(c, "sort") {
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+		column, ok := sortFields[field]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sort tidak dikenal: " + field, "allowed": sortFieldNames(sortFields)})
+			return list, false
+		}
+		order = append(order, column+" "+direction)
+	}
+	if len(order) == 0 {
+		order = append(order, defaultSort)
+	}
+	list.Order = strings.Join(append(order, idColumn+" DESC"), ", ")
+
+	return list, true
+}
+
+// Apply menambahkan urutan, offset dan limit halaman ke query
+func (l listQuery) Apply(query *gorm.DB) *gorm.DB {
+	return query.Order(l.Order).Offset((l.Page - 1) * l.PageSize).Limit(l.PageSize)
+}
+
+// Pagination adalah info halaman untuk response
+func (l listQuery) Pagination(total int64) gin.H {
+	return gin.H{
+		"page":        l.Page,
+		"page_size":   l.PageSize,
+		"total":       total,
+		"total_pages": (total + int64(l.PageSize) - 1) / int64(l.PageSize),
+	}
+}
+
+// queryList membaca parameter yang boleh diulang atau dipisah koma,
+// misalnya ?status=Draft&status=Terkirim atau ?status=Draft,Terkirim
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func sortFieldNames(sortFields map[string]string) []string {
+	names := make([]string, 0, len(sortFields))
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
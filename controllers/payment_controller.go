@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CreatePaymentRequest struct {
+	Amount      models.Money `json:"amount" binding:"required,gt=0"`
+	PaymentDate string       `json:"payment_date"`
+	Method      string       `json:"method"`
+	Reference   string       `json:"reference"`
+}
+
+var (
+	errPaymentExceedsBalance = errors.New("jumlah pembayaran melebihi sisa tagihan")
+	errInvoiceNotPayable     = errors.New("invoice harus berstatus Terkirim atau Lunas Sebagian untuk menerima pembayaran")
+	errPaymentLocked         = errors.New("pembayaran pada invoice ini tidak bisa dihapus")
+)
+
+func CreateInvoicePayment(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoiceID := c.Param("id")
+
+	var req CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	// Default tanggal pembayaran hari ini
+	if req.PaymentDate == "" {
+		req.PaymentDate = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", req.PaymentDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format payment_date harus YYYY-MM-DD"})
+		return
+	}
+
+	var invoice models.Invoice
+	var payment models.InvoicePayment
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris invoice supaya pembayaran bersamaan tidak melebihi saldo
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", invoiceID, userID).
+			First(&invoice).Error; err != nil {
+			return err
+		}
+
+		if !models.IsPayable(invoice.Status) {
+			return errInvoiceNotPayable
+		}
+
+		if req.Amount > invoice.Balance {
+			return errPaymentExceedsBalance
+		}
+
+		payment = models.InvoicePayment{
+			InvoiceID:   invoice.ID,
+			Amount:      req.Amount,
+			PaymentDate: req.PaymentDate,
+			Method:      req.Method,
+			Reference:   req.Reference,
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		return services.SyncInvoiceBalance(tx, &invoice, userID)
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+			return
+		}
+		if errors.Is(err, errPaymentExceedsBalance) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "balance": invoice.Balance})
+			return
+		}
+		if errors.Is(err, errInvoiceNotPayable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "status": invoice.Status})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan pembayaran"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Pembayaran berhasil dicatat", "payment": payment, "invoice": invoice})
+}
+
+func GetInvoicePayments(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoiceID := c.Param("id")
+
+	var invoice models.Invoice
+	err := database.DB.Preload("Payments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("payment_date ASC, id ASC")
+	}).
+		Where("id = ? AND user_id = ?", invoiceID, userID).
+		First(&invoice).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pembayaran"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"payments":    invoice.Payments,
+		"amount":      invoice.Amount,
+		"amount_paid": invoice.AmountPaid,
+		"balance":     invoice.Balance,
+		"status":      invoice.Status,
+	})
+}
+
+func DeleteInvoicePayment(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoiceID := c.Param("id")
+	paymentID := c.Param("paymentId")
+
+	var invoice models.Invoice
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", invoiceID, userID).
+			First(&invoice).Error; err != nil {
+			return err
+		}
+
+		if invoice.Status != models.StatusPartiallyPaid && invoice.Status != models.StatusPaid {
+			return errPaymentLocked
+		}
+
+		var payment models.InvoicePayment
+		if err := tx.Where("id = ? AND invoice_id = ?", paymentID, invoice.ID).First(&payment).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&payment).Error; err != nil {
+			return err
+		}
+
+		return services.SyncInvoiceBalance(tx, &invoice, userID)
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pembayaran tidak ditemukan"})
+			return
+		}
+		if errors.Is(err, errPaymentLocked) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "status": invoice.Status})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus pembayaran"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pembayaran berhasil dihapus", "invoice": invoice})
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+func PortalActivate(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var account models.ClientAccount
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		account, err = services.ActivateClientAccount(tx, input.Token, input.Password)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrClientInviteInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengaktifkan akun portal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Akun portal berhasil diaktifkan", "email": account.Email})
+}
+
+// PortalLogin memakai email client. Satu email bisa menjadi client di beberapa
+// vendor, jadi jika password cocok di lebih dari satu akun, client harus memilih
+// account_id.
+func PortalLogin(c *gin.Context) {
+	var input struct {
+		Email     string `json:"email" binding:"required,email"`
+		Password  string `json:"password" binding:"required"`
+		AccountID uint   `json:"account_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Where("LOWER(email) = LOWER(?) AND activated_at IS NOT NULL AND disabled_at IS NULL", input.Email)
+	if input.AccountID != 0 {
+		query = query.Where("id = ?", input.AccountID)
+	}
+
+	var accounts []models.ClientAccount
+	if err := query.Find(&accounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal login"})
+		return
+	}
+
+	var matched []models.ClientAccount
+	for _, account := range accounts {
+		if utils.CheckPasswordHash(input.Password, account.Password) {
+			matched = append(matched, account)
+		}
+	}
+
+	if len(matched) == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Email atau password salah"})
+		return
+	}
+	if len(matched) > 1 {
+		choices := make([]gin.H, 0, len(matched))
+		for _, account := range matched {
+			var vendor models.User
+			database.DB.First(&vendor, account.UserID)
+			choices = append(choices, gin.H{"account_id": account.ID, "vendor": vendor.Name})
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Email terdaftar di beberapa vendor, sertakan account_id", "accounts": choices})
+		return
+	}
+
+	account := matched[0]
+	token, err := utils.GenerateClientToken(account.ID, account.ClientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal generate token"})
+		return
+	}
+
+	now := time.Now()
+	database.DB.Model(&account).Update("last_login_at", now)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Login berhasil",
+		"token":   token,
+		"account": gin.H{
+			"id":        account.ID,
+			"client_id": account.ClientID,
+			"email":     account.Email,
+		},
+	})
+}
+
+// currentClientAccount memuat akun portal dari token dan memastikan aksesnya
+// belum dicabut
+func currentClientAccount(c *gin.Context) (models.ClientAccount, bool) {
+	var account models.ClientAccount
+	if err := database.DB.First(&account, c.GetUint("clientAccountID")).Error; err != nil || !account.CanLogin() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Akses portal tidak berlaku"})
+		return account, false
+	}
+	return account, true
+}
+
+// portalInvoices membatasi query ke invoice client ini yang sudah dikirim dan
+// tidak dibatalkan. Dipakai untuk daftar, detail maupun PDF invoice portal.
+func portalInvoices(account models.ClientAccount) *gorm.DB {
+	return database.DB.Model(&models.Invoice{}).
+		Where("user_id = ? AND client_id = ? AND status NOT IN ?", account.UserID, account.ClientID,
+			[]string{models.StatusDraft, models.StatusCancelled})
+}
+
+func GetPortalProfile(c *gin.Context) {
+	account, ok := currentClientAccount(c)
+	if !ok {
+		return
+	}
+
+	var client models.Client
+	if err := database.DB.Unscoped().First(&client, account.ClientID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data client"})
+		return
+	}
+
+	var vendor models.User
+	if err := database.DB.First(&vendor, account.UserID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data vendor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"client": client,
+		"vendor": gin.H{"name": vendor.Name, "email": vendor.Email},
+	})
+}
+
+func GetPortalInvoices(c *gin.Context) {
+	account, ok := currentClientAccount(c)
+	if !ok {
+		return
+	}
+
+	var invoices []models.Invoice
+	if err := portalInvoices(account).Order("issue_date DESC, id DESC").Find(&invoices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
+		return
+	}
+
+	// Sisa tagihan per mata uang karena invoice client bisa berbeda mata uang
+	var balances []struct {
+		Currency     string       `json:"currency"`
+		TotalAmount  models.Money `json:"total_amount"`
+		TotalBalance models.Money `json:"total_balance"`
+	}
+	if err := portalInvoices(account).
+		Where("status IN ?", models.PayableStatuses).
+		Select("currency, COALESCE(SUM(amount), 0)::bigint AS total_amount, COALESCE(SUM(balance), 0)::bigint AS total_balance").
+		Group("currency").
+		Order("currency").
+		Scan(&balances).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung sisa tagihan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoices": invoices, "balances": balances})
+}
+
+// findPortalInvoice mengambil satu invoice milik client yang sedang login
+func findPortalInvoice(c *gin.Context, account models.ClientAccount, preloads ...string) (models.Invoice, bool) {
+	var invoice models.Invoice
+	query := portalInvoices(account)
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	if err := query.Where("id = ?", c.Param("id")).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+			return invoice, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
+		return invoice, false
+	}
+	return invoice, true
+}
+
+func GetPortalInvoiceByID(c *gin.Context) {
+	account, ok := currentClientAccount(c)
+	if !ok {
+		return
+	}
+
+	invoice, ok := findPortalInvoice(c, account, "Items", "Payments")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func GetPortalInvoicePDF(c *gin.Context) {
+	account, ok := currentClientAccount(c)
+	if !ok {
+		return
+	}
+
+	invoice, ok := findPortalInvoice(c, account, "Items")
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.RenderInvoicePDF(database.DB, &buf, invoice); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": services.InvoicePDFFilename(&invoice)}))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/pdf"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+var publicInvoiceHTML = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": pdf.FormatMoney,
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Invoice {{.Invoice.Number}}</title>
+<style>
+body { font-family: Arial, sans-serif; max-width: 800px; margin: 2em auto; padding: 0 1em; color: #222; }
+table { width: 100%; border-collapse: collapse; margin: 1em 0; }
+th, td { padding: .5em; text-align: left; border-bottom: 1px solid #ddd; }
+td.num, th.num { text-align: right; }
+.totals td { border: none; }
+</style>
+</head>
+<body>
+<h1>INVOICE {{.Invoice.Number}}</h1>
+<p>Dari: <strong>{{.Sender.LegalName}}</strong> ({{.Sender.Email}})<br>
+{{if .Sender.Address}}{{.Sender.Address}}<br>{{end}}
+Kepada: <strong>{{.Client.Nama}}</strong></p>
+<p>Tanggal: {{.Invoice.IssueDate}}<br>
+Jatuh tempo: {{.Invoice.DueDate}}<br>
+Status: {{.Invoice.Status}}</p>
+<table>
+<tr><th>Keterangan</th><th class="num">Harga</th><th class="num">Jml</th><th class="num">Total</th></tr>
+{{range .Invoice.Items}}<tr><td>{{.ItemName}}</td><td class="num">{{money .UnitPrice $.Invoice.Currency}}</td><td class="num">{{.Quantity}}</td><td class="num">{{money .TotalPrice $.Invoice.Currency}}</td></tr>
+{{end}}</table>
+<table class="totals">
+<tr><td class="num">Sub Total</td><td class="num">{{money .Invoice.Subtotal .Invoice.Currency}}</td></tr>
+{{if .Invoice.DiscountAmount}}<tr><td class="num">Diskon</td><td class="num">-{{money .Invoice.DiscountAmount .Invoice.Currency}}</td></tr>{{end}}
+{{if .Invoice.TaxTotal}}<tr><td class="num">Pajak</td><td class="num">{{money .Invoice.TaxTotal .Invoice.Currency}}</td></tr>{{end}}
+<tr><td class="num"><strong>Total</strong></td><td class="num"><strong>{{money .Invoice.Amount .Invoice.Currency}}</strong></td></tr>
+<tr><td class="num">Sisa tagihan</td><td class="num">{{money .Invoice.Balance .Invoice.Currency}}</td></tr>
+</table>
+{{if .Invoice.Note}}<p>{{.Invoice.Note}}</p>{{end}}
+<p><a href="{{.PDFPath}}">Unduh PDF</a></p>
+</body>
+</html>
+`))
+
+// publicInvoice adalah isi invoice yang boleh dilihat pemegang tautan publik,
+// tanpa ID internal, template maupun detail kurs
+type publicInvoice struct {
+	Number         string              `json:"invoice_number"`
+	IssueDate      string              `json:"issue_date"`
+	DueDate        string              `json:"due_date"`
+	Status         string              `json:"status"`
+	Currency       string              `json:"currency"`
+	Items          []publicInvoiceItem `json:"items"`
+	Subtotal       models.Money        `json:"subtotal"`
+	DiscountAmount models.Money        `json:"discount_amount"`
+	TaxTotal       models.Money        `json:"tax_total"`
+	Amount         models.Money        `json:"amount"`
+	AmountPaid     models.Money        `json:"amount_paid"`
+	CreditedAmount models.Money        `json:"credited_amount"`
+	Balance        models.Money        `json:"balance"`
+	Note           string              `json:"note"`
+}
+
+type publicInvoiceItem struct {
+	ItemName       string          `json:"item_name"`
+	Quantity       int             `json:"quantity"`
+	UnitPrice      models.Money    `json:"unit_price"`
+	TotalPrice     models.Money    `json:"total_price"`
+	DiscountAmount models.Money    `json:"discount_amount"`
+	TaxAmount      models.Money    `json:"tax_amount"`
+	Taxes          []publicItemTax `json:"taxes"`
+}
+
+type publicItemTax struct {
+	Name      string       `json:"name"`
+	Rate      float64      `json:"rate"`
+	Inclusive bool         `json:"inclusive"`
+	Amount    models.Money `json:"amount"`
+}
+
+func newPublicInvoice(invoice models.Invoice) publicInvoice {
+	public := publicInvoice{
+		Number:         invoice.Number,
+		IssueDate:      invoice.IssueDate,
+		DueDate:        invoice.DueDate,
+		Status:         invoice.Status,
+		Currency:       invoice.Currency,
+		Items:          make([]publicInvoiceItem, 0, len(invoice.Items)),
+		Subtotal:       invoice.Subtotal,
+		DiscountAmount: invoice.DiscountAmount,
+		TaxTotal:       invoice.TaxTotal,
+		Amount:         invoice.Amount,
+		AmountPaid:     invoice.AmountPaid,
+		CreditedAmount: invoice.CreditedAmount,
+		Balance:        invoice.Balance,
+		Note:           invoice.Note,
+	}
+	for _, item := range invoice.Items {
+		taxes := make([]publicItemTax, 0, len(item.Taxes))
+		for _, tax := range item.Taxes {
+			taxes = append(taxes, publicItemTax{Name: tax.Name, Rate: tax.Rate, Inclusive: tax.Inclusive, Amount: tax.Amount})
+		}
+		public.Items = append(public.Items, publicInvoiceItem{
+			ItemName:       item.ItemName,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			TotalPrice:     item.TotalPrice,
+			DiscountAmount: item.DiscountAmount,
+			TaxAmount:      item.TaxAmount,
+			Taxes:          taxes,
+		})
+	}
+	return public
+}
+
+type sharedInvoice struct {
+	Invoice models.Invoice
+	Sender  models.BusinessProfile
+	Client  models.Client
+	PDFPath string
+}
+
+// loadSharedInvoice membuka tautan publik dari parameter token, mencatat
+// kunjungan, lalu memuat invoice beserta pengirim dan client-nya
+func loadSharedInvoice(c *gin.Context) (sharedInvoice, bool) {
+	var shared sharedInvoice
+	token := c.Param("token")
+
+	link, err := services.OpenShareLink(database.DB, token, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrShareLinkInvalid) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return shared, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka tautan invoice"})
+		return shared, false
+	}
+
+	// Invoice yang sudah dihapus ikut membuat tautannya tidak berlaku
+	if err := database.DB.Preload("Items").First(&shared.Invoice, link.InvoiceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrShareLinkInvalid.Error()})
+		return shared, false
+	}
+
+	if shared.Sender, err = services.LoadIssuer(database.DB, shared.Invoice.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengirim"})
+		return shared, false
+	}
+	database.DB.Unscoped().Where("id = ?", shared.Invoice.ClientID).Limit(1).Find(&shared.Client)
+
+	shared.PDFPath = "/public/invoices/" + token + "/pdf"
+	return shared, true
+}
+
+// GetPublicInvoice menampilkan invoice lewat tautan publik tanpa login.
+// Browser mendapat HTML, klien lain JSON; bisa dipaksa dengan ?format=html|json.
+func GetPublicInvoice(c *gin.Context) {
+	shared, ok := loadSharedInvoice(c)
+	if !ok {
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = "json"
+		if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+			format = "html"
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if format == "html" {
+		var page bytes.Buffer
+		if err := publicInvoiceHTML.Execute(&page, shared); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menampilkan invoice"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invoice": newPublicInvoice(shared.Invoice),
+		"from":    gin.H{"name": shared.Sender.LegalName, "email": shared.Sender.Email, "address": shared.Sender.Address, "phone": shared.Sender.Phone},
+		"to":      gin.H{"name": shared.Client.Nama, "email": shared.Client.Email},
+		"pdf_url": shared.PDFPath,
+	})
+}
+
+func GetPublicInvoicePDF(c *gin.Context) {
+	shared, ok := loadSharedInvoice(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.RenderInvoicePDF(database.DB, &buf, shared.Invoice); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": services.InvoicePDFFilename(&shared.Invoice)}))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,366 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/pdf"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CreateQuoteRequest struct {
+	ClientID      uint               `json:"client_id"`
+	IssueDate     string             `json:"issue_date"`  // default hari ini
+	ExpiryDate    string             `json:"expiry_date"` // default 30 hari setelah issue_date
+	Note          string             `json:"note"`
+	DiscountType  string             `json:"discount_type"` // "percent" atau "fixed"
+	DiscountValue models.Money       `json:"discount_value"`
+	Currency      string             `json:"currency"`      // kosong = mata uang client / user
+	ExchangeRate  float64            `json:"exchange_rate"` // opsional, isi manual jika kurs tidak ada di file
+	Items         []models.QuoteItem `json:"items"`
+}
+
+type ConvertQuoteRequest struct {
+	IssueDate string `json:"issue_date"` // default hari ini
+	DueDate   string `json:"due_date"`   // default 30 hari setelah issue_date
+}
+
+var (
+	errQuoteNotFound    = errors.New("Quote tidak ditemukan")
+	errQuoteNotEditable = errors.New("quote tidak bisa diubah")
+)
+
+// userTodayDate mengembalikan tanggal hari ini menurut zona waktu user,
+// dipakai sebagai tanggal terbit bawaan
+func userTodayDate(userID uint) (string, error) {
+	setting, err := services.LoadUserSetting(database.DB, userID)
+	if err != nil {
+		return "", err
+	}
+	return services.UserToday(setting, time.Now()).Format("2006-01-02"), nil
+}
+
+// applyQuoteDates mengisi tanggal terbit dan masa berlaku default
+func applyQuoteDates(req *CreateQuoteRequest, userID uint) error {
+	if req.IssueDate == "" {
+		today, err := userTodayDate(userID)
+		if err != nil {
+			return err
+		}
+		req.IssueDate = today
+	}
+	if req.ExpiryDate == "" {
+		if issue, err := time.Parse("2006-01-02", req.IssueDate); err == nil {
+			req.ExpiryDate = issue.AddDate(0, 0, models.DefaultQuoteValidDays).Format("2006-01-02")
+		}
+	}
+	return nil
+}
+
+// lockQuote mengambil quote milik user beserta itemnya dengan kunci baris
+func lockQuote(tx *gorm.DB, quoteID string, userID uint) (models.Quote, error) {
+	var quote models.Quote
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", quoteID, userID).
+		First(&quote).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return quote, errQuoteNotFound
+	}
+	if err != nil {
+		return quote, err
+	}
+	err = tx.Where("quote_id = ?", quote.ID).Order("id").Find(&quote.Items).Error
+	return quote, err
+}
+
+// respondQuoteError menerjemahkan error quote menjadi status HTTP
+func respondQuoteError(c *gin.Context, err error, message string) {
+	var transitionErr *services.TransitionError
+	switch {
+	case errors.Is(err, errQuoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrQuoteConverted), errors.Is(err, errQuoteNotEditable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   transitionErr.Error(),
+			"status":  transitionErr.From,
+			"allowed": transitionErr.Allowed,
+		})
+	default:
+		respondServiceError(c, err, message)
+	}
+}
+
+func CreateQuote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req CreateQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	if err := applyQuoteDates(&req, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	quote := models.Quote{
+		UserID:        userID,
+		ClientID:      req.ClientID,
+		IssueDate:     req.IssueDate,
+		ExpiryDate:    req.ExpiryDate,
+		Note:          req.Note,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		Items:         req.Items,
+	}
+
+	// Nomor dialokasikan di transaksi yang sama agar tidak bolong jika gagal
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.CreateQuote(tx, &quote, services.InvoiceOptions{
+			Currency:     req.Currency,
+			ExchangeRate: req.ExchangeRate,
+		})
+	})
+	if err != nil {
+		respondServiceError(c, err, "Gagal menyimpan quote")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Quote berhasil dibuat", "quote": quote})
+}
+
+func GetQuotes(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Where("user_id = ?", userID)
+	if clientID := c.Query("client_id"); clientID != "" {
+		query = query.Where("client_id = ?", clientID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var quotes []models.Quote
+	if err := query.Preload("Items").Order("created_at DESC").Find(&quotes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotes": quotes})
+}
+
+func GetQuoteByID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var quote models.Quote
+	if err := database.DB.Preload("Items").
+		Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		First(&quote).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// UpdateQuote mengganti isi quote yang belum diterima, ditolak atau dikonversi.
+// Quote kedaluwarsa bisa diperpanjang lalu dikirim ulang.
+func UpdateQuote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req CreateQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	if err := applyQuoteDates(&req, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	var quote models.Quote
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if quote, err = lockQuote(tx, c.Param("id"), userID); err != nil {
+			return err
+		}
+		if quote.InvoiceID != nil {
+			return services.ErrQuoteConverted
+		}
+		if !models.IsQuoteEditable(quote.Status) {
+			return fmt.Errorf("Quote dengan status %s tidak bisa diubah: %w", quote.Status, errQuoteNotEditable)
+		}
+
+		quote.ClientID = req.ClientID
+		quote.IssueDate = req.IssueDate
+		quote.ExpiryDate = req.ExpiryDate
+		quote.Note = req.Note
+		quote.DiscountType = req.DiscountType
+		quote.DiscountValue = req.DiscountValue
+		quote.Items = req.Items
+
+		if err := services.PrepareQuote(tx, &quote, services.InvoiceOptions{
+			Currency:     req.Currency,
+			ExchangeRate: req.ExchangeRate,
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Where("quote_id = ?", quote.ID).Delete(&models.QuoteItem{}).Error; err != nil {
+			return err
+		}
+		for i := range quote.Items {
+			quote.Items[i].ID = 0
+		}
+		return tx.Save(&quote).Error
+	})
+	if err != nil {
+		respondQuoteError(c, err, "Gagal memperbarui quote")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote berhasil diperbarui", "quote": quote})
+}
+
+func DeleteQuote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		quote, err := lockQuote(tx, c.Param("id"), userID)
+		if err != nil {
+			return err
+		}
+		// Quote yang sudah jadi invoice tetap disimpan sebagai asal-usul invoice
+		if quote.InvoiceID != nil {
+			return services.ErrQuoteConverted
+		}
+		return tx.Delete(&quote).Error
+	})
+	if err != nil {
+		respondQuoteError(c, err, "Gagal menghapus quote")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote berhasil dihapus"})
+}
+
+// transitionQuoteByID memindahkan status quote milik user yang sedang login
+func transitionQuoteByID(c *gin.Context, to, message string) {
+	userID := c.GetUint("userID")
+
+	var quote models.Quote
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if quote, err = lockQuote(tx, c.Param("id"), userID); err != nil {
+			return err
+		}
+		return services.TransitionQuote(tx, &quote, to)
+	})
+	if err != nil {
+		respondQuoteError(c, err, "Gagal mengubah status quote")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message, "quote": quote})
+}
+
+// SendQuote menandai quote sudah dikirim ke client
+func SendQuote(c *gin.Context) {
+	transitionQuoteByID(c, models.QuoteStatusSent, "Quote ditandai terkirim")
+}
+
+func AcceptQuote(c *gin.Context) {
+	transitionQuoteByID(c, models.QuoteStatusAccepted, "Quote diterima")
+}
+
+func DeclineQuote(c *gin.Context) {
+	transitionQuoteByID(c, models.QuoteStatusDeclined, "Quote ditolak")
+}
+
+// ConvertQuote membuat invoice Draft dari quote dengan client dan item yang sama
+func ConvertQuote(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	// Body opsional, konversi cukup dengan satu klik
+	var req ConvertQuoteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+			return
+		}
+	}
+	if req.IssueDate == "" {
+		today, err := userTodayDate(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+			return
+		}
+		req.IssueDate = today
+	}
+	if req.DueDate == "" {
+		if issue, err := time.Parse("2006-01-02", req.IssueDate); err == nil {
+			req.DueDate = issue.AddDate(0, 0, 30).Format("2006-01-02")
+		}
+	}
+
+	var quote models.Quote
+	var invoice *models.Invoice
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if quote, err = lockQuote(tx, c.Param("id"), userID); err != nil {
+			return err
+		}
+		invoice, err = services.ConvertQuote(tx, &quote, req.IssueDate, req.DueDate, userID)
+		return err
+	})
+	if err != nil {
+		respondQuoteError(c, err, "Gagal mengonversi quote")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Quote berhasil dikonversi menjadi invoice", "invoice": invoice, "quote": quote})
+}
+
+func ExportQuotePDF(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var quote models.Quote
+	if err := database.DB.Preload("Items").
+		Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		First(&quote).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quote tidak ditemukan"})
+		return
+	}
+
+	issuer, err := services.LoadIssuer(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "gagal mengambil profil usaha"})
+		return
+	}
+
+	var client models.Client
+	database.DB.Unscoped().Where("id = ?", quote.ClientID).Limit(1).Find(&client)
+
+	var buf bytes.Buffer
+	if err := pdf.RenderQuote(&buf, quote, issuer, client); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename=quote.pdf")
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+)
+
+type RecurringInvoiceRequest struct {
+	Name          string                        `json:"name"`
+	ClientID      uint                          `json:"client_id" binding:"required"`
+	Currency      string                        `json:"currency"`
+	Note          string                        `json:"note"`
+	DiscountType  string                        `json:"discount_type"`
+	DiscountValue models.Money                  `json:"discount_value"`
+	DueDays       int                           `json:"due_days" binding:"gte=0"`
+	IntervalUnit  string                        `json:"interval_unit" binding:"required"`
+	IntervalCount int                           `json:"interval_count"`
+	CronExpr      string                        `json:"cron_expr"`
+	StartDate     string                        `json:"start_date" binding:"required"`
+	EndDate       string                        `json:"end_date"`
+	AutoSend      bool                          `json:"auto_send"`
+	Active        *bool                         `json:"active"` // default true
+	Items         []models.RecurringInvoiceItem `json:"items"`
+}
+
+// apply menyalin isi request ke template
+func (req *RecurringInvoiceRequest) apply(r *models.RecurringInvoice) {
+	r.Name = req.Name
+	r.ClientID = req.ClientID
+	r.Currency = req.Currency
+	r.Note = req.Note
+	r.DiscountType = req.DiscountType
+	r.DiscountValue = req.DiscountValue
+	r.DueDays = req.DueDays
+	r.IntervalUnit = req.IntervalUnit
+	r.IntervalCount = req.IntervalCount
+	r.CronExpr = req.CronExpr
+	r.StartDate = req.StartDate
+	r.EndDate = req.EndDate
+	r.AutoSend = req.AutoSend
+	r.Active = req.Active == nil || *req.Active
+	r.Items = req.Items
+	for i := range r.Items {
+		r.Items[i].ID = 0
+	}
+}
+
+func CreateRecurringInvoice(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req RecurringInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	recurring := models.RecurringInvoice{UserID: userID}
+	req.apply(&recurring)
+
+	if err := services.ValidateRecurringInvoice(database.DB, &recurring); err != nil {
+		respondServiceError(c, err, "Gagal memvalidasi invoice berulang")
+		return
+	}
+
+	if err := services.ScheduleFirstRun(&recurring); err != nil {
+		respondServiceError(c, err, "Gagal menghitung jadwal invoice berulang")
+		return
+	}
+
+	if err := database.DB.Create(&recurring).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan invoice berulang"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Invoice berulang berhasil dibuat", "recurring_invoice": recurring})
+}
+
+func GetRecurringInvoices(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var recurring []models.RecurringInvoice
+	if err := database.DB.Preload("Items").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&recurring).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice berulang"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recurring_invoices": recurring})
+}
+
+func GetRecurringInvoiceByID(c *gin.Context) {
+	userID := c.GetUint("userID")
+	recurringID := c.Param("id")
+
+	var recurring models.RecurringInvoice
+	if err := database.DB.Preload("Items").
+		Where("id = ? AND user_id = ?", recurringID, userID).
+		First(&recurring).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice berulang tidak ditemukan"})
+		return
+	}
+
+	// Invoice yang sudah dibuat dari template ini
+	var invoices []models.Invoice
+	if err := database.DB.Where("recurring_invoice_id = ? AND user_id = ?", recurring.ID, userID).
+		Order("issue_date DESC").
+		Find(&invoices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recurring_invoice": recurring, "invoices": invoices})
+}
+
+func UpdateRecurringInvoice(c *gin.Context) {
+	userID := c.GetUint("userID")
+	recurringID := c.Param("id")
+
+	var recurring models.RecurringInvoice
+	if err := database.DB.Where("id = ? AND user_id = ?", recurringID, userID).First(&recurring).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice berulang tidak ditemukan"})
+		return
+	}
+
+	var req RecurringInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	req.apply(&recurring)
+
+	if err := services.ValidateRecurringInvoice(database.DB, &recurring); err != nil {
+		respondServiceError(c, err, "Gagal memvalidasi invoice berulang")
+		return
+	}
+
+	// Jadwal dilanjutkan setelah invoice terakhir, jadwal lama tidak dibuat ulang
+	if err := services.ScheduleFirstRun(&recurring); err != nil {
+		respondServiceError(c, err, "Gagal menghitung jadwal invoice berulang")
+		return
+	}
+	recurring.LastError = ""
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("recurring_invoice_id = ?", recurring.ID).Delete(&models.RecurringInvoiceItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(&recurring).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui invoice berulang"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invoice berulang berhasil diperbarui", "recurring_invoice": recurring})
+}
+
+func DeleteRecurringInvoice(c *gin.Context) {
+	userID := c.GetUint("userID")
+	recurringID := c.Param("id")
+
+	var recurring models.RecurringInvoice
+	if err := database.DB.Where("id = ? AND user_id = ?", recurringID, userID).First(&recurring).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice berulang tidak ditemukan"})
+		return
+	}
+
+	// Invoice yang sudah dibuat tetap ada, hanya template yang dihapus
+	if err := database.DB.Delete(&recurring).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus invoice berulang"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invoice berulang berhasil dihapus"})
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+type ReminderRuleRequest struct {
+	OffsetDays int    `json:"offset_days" binding:"gte=-365,lte=365"`
+	Tone       string `json:"tone" binding:"omitempty,oneof=friendly firm urgent final"` // kosong = mengikuti offset_days
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+	Active     *bool  `json:"active"` // default true
+}
+
+// validate memastikan template pengingat bisa dirender sebelum disimpan
+func (r ReminderRuleRequest) validate() error {
+	for _, text := range []string{r.Subject, r.Body} {
+		if text == "" {
+			continue
+		}
+		if err := services.ValidateInvoiceEmailTemplate(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r ReminderRuleRequest) apply(rule *models.ReminderRule) {
+	rule.OffsetDays = r.OffsetDays
+	rule.Tone = r.Tone
+	rule.Subject = r.Subject
+	rule.Body = r.Body
+	if r.Active != nil {
+		rule.Active = *r.Active
+	}
+}
+
+func CreateReminderRule(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input ReminderRuleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.ReminderRule{UserID: userID, Active: true}
+	input.apply(&rule)
+
+	if err := database.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan aturan pengingat"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Aturan pengingat berhasil ditambahkan", "reminder_rule": rule})
+}
+
+func GetReminderRules(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var rules []models.ReminderRule
+	if err := database.DB.Where("user_id = ?", userID).Order("offset_days ASC").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data aturan pengingat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reminder_rules": rules})
+}
+
+func UpdateReminderRule(c *gin.Context) {
+	userID := c.GetUint("userID")
+	ruleID := c.Param("id")
+
+	var rule models.ReminderRule
+	if err := database.DB.Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Aturan pengingat tidak ditemukan"})
+		return
+	}
+
+	var input ReminderRuleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input.apply(&rule)
+
+	if err := database.DB.Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update aturan pengingat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Aturan pengingat berhasil diupdate", "reminder_rule": rule})
+}
+
+func DeleteReminderRule(c *gin.Context) {
+	userID := c.GetUint("userID")
+	ruleID := c.Param("id")
+
+	var rule models.ReminderRule
+	if err := database.DB.Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Aturan pengingat tidak ditemukan"})
+		return
+	}
+
+	// Riwayat pengingat yang sudah terkirim tetap tersimpan di invoice
+	if err := database.DB.Delete(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus aturan pengingat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Aturan pengingat berhasil dihapus"})
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/pdf"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+// reportDateRange membaca ?from= dan ?to= (YYYY-MM-DD). Default-nya awal tahun
+// sampai hari ini menurut zona waktu user.
+func reportDateRange(c *gin.Context, userID uint) (from, to string, ok bool) {
+	setting, err := services.LoadUserSetting(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return "", "", false
+	}
+	today := services.UserToday(setting, time.Now())
+
+	from = c.DefaultQuery("from", time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"))
+	to = c.DefaultQuery("to", today.Format("2006-01-02"))
+
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format from harus YYYY-MM-DD"})
+		return "", "", false
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format to harus YYYY-MM-DD"})
+		return "", "", false
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from tidak boleh setelah to"})
+		return "", "", false
+	}
+	return from, to, true
+}
+
+// GetReportSummary mengembalikan ringkasan pendapatan dan piutang untuk dashboard
+func GetReportSummary(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	from, to, ok := reportDateRange(c, userID)
+	if !ok {
+		return
+	}
+
+	top, err := strconv.Atoi(c.DefaultQuery("top", "5"))
+	if err != nil || top < 0 || top > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top harus angka 0 sampai 50"})
+		return
+	}
+
+	summary, err := services.BuildReportSummary(database.DB, userID, from, to, top)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung laporan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// loadAgingReport membaca ?as_of= (default hari ini menurut zona waktu user) lalu
+// menghitung laporan umur piutang
+func loadAgingReport(c *gin.Context, userID uint) (models.AgingReport, bool) {
+	asOf := c.Query("as_of")
+	if asOf == "" {
+		setting, err := services.LoadUserSetting(database.DB, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+			return models.AgingReport{}, false
+		}
+		asOf = services.UserToday(setting, time.Now()).Format("2006-01-02")
+	}
+
+	report, err := services.BuildAgingReport(database.DB, userID, asOf)
+	if err != nil {
+		respondServiceError(c, err, "Gagal menghitung umur piutang")
+		return report, false
+	}
+	return report, true
+}
+
+// GetAgingReport mengembalikan umur piutang per client dalam bentuk JSON
+func GetAgingReport(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	report, ok := loadAgingReport(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func ExportAgingReportPDF(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	report, ok := loadAgingReport(c, userID)
+	if !ok {
+		return
+	}
+
+	issuer, err := services.LoadIssuer(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "gagal mengambil profil usaha"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.RenderAgingReport(&buf, report, issuer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=umur-piutang-%s.pdf", report.AsOf))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+// Search mencari client dan invoice milik user dengan index full-text.
+// ?q= wajib, ?type=client,invoice membatasi jenis hasil, ?limit= maksimal 50.
+func Search(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parameter q wajib diisi"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit harus angka 1 sampai 50"})
+		return
+	}
+
+	types := queryList(c, "type")
+	for _, t := range types {
+		if t != services.SearchTypeClient && t != services.SearchTypeInvoice {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type harus client atau invoice"})
+			return
+		}
+	}
+
+	hits, err := services.Search(database.DB, userID, q, types, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal melakukan pencarian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": q, "results": hits})
+}
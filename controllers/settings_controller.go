@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/services"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm/clause"
+)
+
+func GetSettings(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	setting, err := services.LoadUserSetting(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": setting})
+}
+
+// UpdateSettings mengubah sebagian pengaturan. Field yang tidak dikirim tidak
+// diubah, string kosong mengembalikan field ke nilai bawaan.
+func UpdateSettings(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input struct {
+		InvoiceNumberPattern    *string `json:"invoice_number_pattern"`
+		InvoiceNumberReset      *string `json:"invoice_number_reset" binding:"omitempty,oneof=yearly monthly"`
+		CreditNoteNumberPattern *string `json:"credit_note_number_pattern"`
+		QuoteNumberPattern      *string `json:"quote_number_pattern"`
+		BaseCurrency            *string `json:"base_currency"`
+		Timezone                *string `json:"timezone"`
+		InvoiceEmailSubject     *string `json:"invoice_email_subject"`
+		InvoiceEmailBody        *string `json:"invoice_email_body"`
+		InvoiceTemplate         *string `json:"invoice_template"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setting, err := services.LoadUserSetting(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	// Pola nomor credit note dan quote memakai reset yang sama dengan invoice
+	numberingChanged := false
+	for _, field := range []struct {
+		input  *string
+		target *string
+	}{
+		{input.InvoiceNumberPattern, &setting.InvoiceNumberPattern},
+		{input.InvoiceNumberReset, &setting.InvoiceNumberReset},
+		{input.CreditNoteNumberPattern, &setting.CreditNoteNumberPattern},
+		{input.QuoteNumberPattern, &setting.QuoteNumberPattern},
+	} {
+		if field.input != nil {
+			*field.target = strings.TrimSpace(*field.input)
+			numberingChanged = true
+		}
+	}
+	setting.ApplyDefaults()
+
+	// Semua pola diperiksa terhadap reset yang berlaku, termasuk pola yang tidak diubah
+	if numberingChanged {
+		for _, pattern := range []string{setting.InvoiceNumberPattern, setting.CreditNoteNumberPattern, setting.QuoteNumberPattern} {
+			if err := utils.ValidateNumberPattern(pattern, setting.InvoiceNumberReset); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	// Mata uang dasar hanya berlaku untuk invoice baru, kurs invoice lama tidak diubah
+	if input.BaseCurrency != nil && *input.BaseCurrency != "" {
+		setting.BaseCurrency = utils.NormalizeCurrency(*input.BaseCurrency)
+		if setting.BaseCurrency == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "kode mata uang harus 3 huruf, misalnya IDR atau USD"})
+			return
+		}
+	}
+
+	// Zona waktu menentukan kapan invoice dianggap lewat jatuh tempo
+	if input.Timezone != nil && *input.Timezone != "" {
+		if _, err := time.LoadLocation(*input.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "zona waktu tidak dikenal, gunakan format IANA seperti Asia/Jakarta"})
+			return
+		}
+		setting.Timezone = *input.Timezone
+	}
+
+	// Template email kosong berarti kembali ke template bawaan
+	if input.InvoiceEmailSubject != nil {
+		if err := services.ValidateInvoiceEmailTemplate(*input.InvoiceEmailSubject); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		setting.InvoiceEmailSubject = *input.InvoiceEmailSubject
+	}
+	if input.InvoiceEmailBody != nil {
+		if err := services.ValidateInvoiceEmailTemplate(*input.InvoiceEmailBody); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		setting.InvoiceEmailBody = *input.InvoiceEmailBody
+	}
+
+	// Template PDF default untuk invoice yang tidak memilih template sendiri
+	if input.InvoiceTemplate != nil {
+		if *input.InvoiceTemplate != "" {
+			if _, err := services.FindInvoiceTemplate(database.DB, userID, *input.InvoiceTemplate); err != nil {
+				respondServiceError(c, err, "Gagal mengambil template PDF")
+				return
+			}
+		}
+		setting.InvoiceTemplate = *input.InvoiceTemplate
+	}
+	setting.ApplyDefaults()
+
+	// Upsert per user, supaya dua penyimpanan pertama yang bersamaan tidak bentrok
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		UpdateAll: true,
+	}).Create(&setting).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan pengaturan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pengaturan berhasil disimpan", "settings": setting})
+}
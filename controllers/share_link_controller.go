@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+)
+
+type ShareLinkRequest struct {
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,gte=1,lte=365"` // default 30 hari
+}
+
+// findUserInvoice mengambil invoice milik user dan membalas 404/500 jika gagal
+func findUserInvoice(c *gin.Context, invoiceID string, userID uint) (models.Invoice, bool) {
+	var invoice models.Invoice
+	if err := database.DB.Where("id = ? AND user_id = ?", invoiceID, userID).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice tidak ditemukan"})
+			return invoice, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data invoice"})
+		return invoice, false
+	}
+	return invoice, true
+}
+
+func CreateInvoiceShareLink(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	var req ShareLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+			return
+		}
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = services.DefaultShareLinkDays
+	}
+
+	invoice, ok := findUserInvoice(c, c.Param("id"), userID)
+	if !ok {
+		return
+	}
+
+	link, err := services.CreateShareLink(database.DB, &invoice, req.ExpiresInDays)
+	if err != nil {
+		respondServiceError(c, err, "Gagal membuat tautan invoice")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Tautan invoice berhasil dibuat", "share_link": link})
+}
+
+func GetInvoiceShareLinks(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoice, ok := findUserInvoice(c, c.Param("id"), userID)
+	if !ok {
+		return
+	}
+
+	var links []models.InvoiceShareLink
+	if err := database.DB.Where("invoice_id = ?", invoice.ID).Order("created_at DESC").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tautan invoice"})
+		return
+	}
+
+	// Token hanya ditampilkan untuk tautan yang masih bisa dibuka
+	now := time.Now()
+	for i := range links {
+		if !links[i].IsActive(now) {
+			continue
+		}
+		if err := services.AttachShareToken(&links[i]); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token tautan"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share_links": links})
+}
+
+func RevokeInvoiceShareLink(c *gin.Context) {
+	// Ambil userID dari context
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	invoice, ok := findUserInvoice(c, c.Param("id"), userID)
+	if !ok {
+		return
+	}
+
+	var link models.InvoiceShareLink
+	if err := database.DB.Where("id = ? AND invoice_id = ?", c.Param("linkId"), invoice.ID).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tautan invoice tidak ditemukan"})
+		return
+	}
+
+	if link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+		if err := database.DB.Model(&link).Update("revoked_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut tautan invoice"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tautan invoice berhasil dicabut", "share_link": link})
+}
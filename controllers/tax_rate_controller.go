@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+)
+
+var errTaxRateInUse = errors.New("tarif pajak masih dipakai invoice berulang, lepaskan dari template terlebih dahulu")
+
+type TaxRateRequest struct {
+	Name      string  `json:"name" binding:"required"`
+	Rate      float64 `json:"rate" binding:"gte=0,lte=100"`
+	Inclusive bool    `json:"inclusive"`
+	Compound  bool    `json:"compound"`
+}
+
+func CreateTaxRate(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var input TaxRateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Inclusive && input.Compound {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pajak inclusive tidak bisa sekaligus compound"})
+		return
+	}
+
+	taxRate := models.TaxRate{
+		UserID:    userID,
+		Name:      input.Name,
+		Rate:      input.Rate,
+		Inclusive: input.Inclusive,
+		Compound:  input.Compound,
+	}
+
+	if err := database.DB.Create(&taxRate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan tarif pajak"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Tarif pajak berhasil ditambahkan", "tax_rate": taxRate})
+}
+
+func GetTaxRates(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var taxRates []models.TaxRate
+	if err := database.DB.Where("user_id = ?", userID).Order("name ASC").Find(&taxRates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data tarif pajak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tax_rates": taxRates})
+}
+
+func UpdateTaxRate(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taxRateID := c.Param("id")
+
+	var taxRate models.TaxRate
+	if err := database.DB.Where("id = ? AND user_id = ?", taxRateID, userID).First(&taxRate).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarif pajak tidak ditemukan"})
+		return
+	}
+
+	var input TaxRateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Inclusive && input.Compound {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pajak inclusive tidak bisa sekaligus compound"})
+		return
+	}
+
+	// Invoice lama menyimpan salinan tarif, jadi perubahan hanya berlaku untuk invoice baru
+	taxRate.Name = input.Name
+	taxRate.Rate = input.Rate
+	taxRate.Inclusive = input.Inclusive
+	taxRate.Compound = input.Compound
+
+	if err := database.DB.Save(&taxRate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update tarif pajak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tarif pajak berhasil diupdate", "tax_rate": taxRate})
+}
+
+func DeleteTaxRate(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taxRateID := c.Param("id")
+
+	var taxRate models.TaxRate
+	if err := database.DB.Where("id = ? AND user_id = ?", taxRateID, userID).First(&taxRate).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarif pajak tidak ditemukan"})
+		return
+	}
+
+	var recurringIDs []uint
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Invoice berulang membaca ulang tarif setiap jadwal, jadi tarifnya harus tetap ada
+		var err error
+		if recurringIDs, err = services.RecurringInvoicesUsingTaxRate(tx, userID, taxRate.ID); err != nil {
+			return err
+		}
+		if len(recurringIDs) > 0 {
+			return errTaxRateInUse
+		}
+
+		if err := tx.Delete(&taxRate).Error; err != nil {
+			return err
+		}
+
+		// Item katalog yang memakai tarif ini tidak lagi punya pajak default
+		return tx.Model(&models.CatalogItem{}).
+			Where("user_id = ? AND tax_rate_id = ?", userID, taxRate.ID).
+			Update("tax_rate_id", nil).Error
+	})
+	if errors.Is(err, errTaxRateInUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "recurring_invoice_ids": recurringIDs})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus tarif pajak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tarif pajak berhasil dihapus"})
+}
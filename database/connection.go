@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -22,7 +23,8 @@ func ConnectDB() {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		host, user, password, dbname, port)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError mengubah pelanggaran index unik menjadi gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -30,6 +32,11 @@ func ConnectDB() {
 	DB = db
 	fmt.Println("🚀 Connected to PostgreSQL!")
 
+	// ubah kolom nominal float lama menjadi sen sebelum AutoMigrate
+	if err := migrateMoneyColumns(db); err != nil {
+		log.Fatal("❌ Failed to migrate money columns:", err)
+	}
+
 	err = db.AutoMigrate(&models.User{})
 	if err != nil {
 		log.Fatal("❌ Failed to migrate User model:", err)
@@ -52,4 +59,113 @@ func ConnectDB() {
 	if err != nil {
 		log.Fatal("❌ Failed to migrate Client model:", err)
 	}
+
+	// migrate tabel pembayaran invoice
+	err = db.AutoMigrate(&models.InvoicePayment{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate InvoicePayment model:", err)
+	}
+
+	// migrate tabel riwayat status invoice
+	err = db.AutoMigrate(&models.InvoiceStatusHistory{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate InvoiceStatusHistory model:", err)
+	}
+
+	// migrate tabel tarif pajak
+	err = db.AutoMigrate(&models.TaxRate{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate TaxRate model:", err)
+	}
+
+	// migrate tabel invoice berulang
+	err = db.AutoMigrate(&models.RecurringInvoice{}, &models.RecurringInvoiceItem{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate RecurringInvoice model:", err)
+	}
+
+	// migrate tabel log email invoice
+	err = db.AutoMigrate(&models.InvoiceEmailLog{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate InvoiceEmailLog model:", err)
+	}
+
+	// migrate tabel aturan dan log pengingat pembayaran
+	err = db.AutoMigrate(&models.ReminderRule{}, &models.InvoiceReminder{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate ReminderRule model:", err)
+	}
+
+	// migrate tabel tautan publik invoice
+	err = db.AutoMigrate(&models.InvoiceShareLink{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate InvoiceShareLink model:", err)
+	}
+
+	// migrate tabel akun portal client
+	err = db.AutoMigrate(&models.ClientAccount{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate ClientAccount model:", err)
+	}
+
+	// migrate tabel credit note
+	err = db.AutoMigrate(&models.CreditNote{}, &models.CreditNoteItem{}, &models.CreditNoteAllocation{}, &models.CreditNoteRefund{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate CreditNote model:", err)
+	}
+
+	// migrate tabel katalog produk dan jasa
+	err = db.AutoMigrate(&models.CatalogItem{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate CatalogItem model:", err)
+	}
+
+	// migrate tabel quote
+	err = db.AutoMigrate(&models.Quote{}, &models.QuoteItem{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate Quote model:", err)
+	}
+
+	// migrate tabel profil usaha penerbit dokumen
+	err = db.AutoMigrate(&models.BusinessProfile{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate BusinessProfile model:", err)
+	}
+
+	// migrate tabel template PDF invoice buatan user
+	err = db.AutoMigrate(&models.InvoiceTemplate{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate InvoiceTemplate model:", err)
+	}
+
+	// migrate tabel pengaturan user dan nomor urut dokumen
+	err = db.AutoMigrate(&models.UserSetting{}, &models.DocumentSequence{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate UserSetting model:", err)
+	}
+
+	// penanda migrasi data yang hanya boleh jalan sekali
+	err = db.AutoMigrate(&schemaMigration{})
+	if err != nil {
+		log.Fatal("❌ Failed to migrate schema migration marker:", err)
+	}
+
+	// index full-text untuk GET /api/search
+	for _, statement := range services.SearchIndexes {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Fatal("❌ Failed to create search index:", err)
+		}
+	}
+
+	if err := runOnce(db, "migrate_invoice_statuses", migrateInvoiceStatuses); err != nil {
+		log.Fatal("❌ Failed to migrate invoice statuses:", err)
+	}
+
+	if err := runOnce(db, "backfill_invoice_totals", backfillInvoiceTotals); err != nil {
+		log.Fatal("❌ Failed to backfill invoice totals:", err)
+	}
+
+	if err := backfillInvoiceNumbers(db); err != nil {
+		log.Fatal("❌ Failed to backfill invoice numbers:", err)
+	}
 }
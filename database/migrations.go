@@ -0,0 +1,162 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+)
+
+// schemaMigration mencatat migrasi data satu kali yang sudah dijalankan
+type schemaMigration struct {
+	Name      string `gorm:"primaryKey"`
+	AppliedAt time.Time
+}
+
+// runOnce menjalankan migrasi data bernama name satu kali saja. Migrasi dan
+// penandanya disimpan di transaksi yang sama supaya migrasi yang gagal
+// dicoba lagi saat start berikutnya.
+func runOnce(db *gorm.DB, name string, migrate func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Name: name, AppliedAt: time.Now()}).Error
+	})
+}
+
+// backfillInvoiceTotals mengisi subtotal dan dasar pajak invoice lama yang
+// dibuat sebelum ada pajak per item. Item baru yang dasar pajaknya nol karena
+// habis oleh diskon tidak ikut diubah.
+func backfillInvoiceTotals(db *gorm.DB) error {
+	if err := db.Exec(`UPDATE invoice_items SET taxable_amount = total_price
+		WHERE taxable_amount = 0 AND tax_amount = 0 AND total_price <> 0
+		AND discount_amount = 0 AND invoice_discount = 0`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE invoices SET subtotal = amount
+		WHERE subtotal = 0 AND tax_total = 0 AND discount_amount = 0 AND amount <> 0`).Error; err != nil {
+		return err
+	}
+	// invoice lama semuanya rupiah dengan kurs 1
+	return db.Exec(`UPDATE invoices SET base_amount = ROUND(amount * exchange_rate)
+		WHERE base_amount = 0 AND amount <> 0`).Error
+}
+
+// backfillInvoiceNumbers memberi nomor pada invoice lama yang dibuat sebelum
+// penomoran, berurutan menurut tanggal terbit per user
+func backfillInvoiceNumbers(db *gorm.DB) error {
+	var invoices []models.Invoice
+	if err := db.Unscoped().
+		Where("number = '' OR number IS NULL").
+		Order("user_id, issue_date, id").
+		Find(&invoices).Error; err != nil {
+		return err
+	}
+
+	for _, invoice := range invoices {
+		// Tanggal terbit lama tidak divalidasi, pakai tanggal dibuat jika tidak valid
+		issueDate := invoice.IssueDate
+		if _, err := time.Parse("2006-01-02", issueDate); err != nil {
+			issueDate = invoice.CreatedAt.Format("2006-01-02")
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			number, err := services.NextInvoiceNumber(tx, invoice.UserID, issueDate)
+			if err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&invoice).Update("number", number).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moneyColumns adalah kolom nominal yang dulu bertipe double precision (rupiah)
+// dan sekarang bigint dalam sen
+var moneyColumns = map[string][]string{
+	"invoices": {"subtotal", "discount_value", "discount_amount", "tax_total", "amount", "amount_paid", "balance"},
+	"invoice_items": {"unit_price", "total_price", "discount_value", "discount_amount",
+		"invoice_discount", "taxable_amount", "tax_amount"},
+	"invoice_payments": {"amount"},
+}
+
+// migrateMoneyColumns mengubah kolom nominal float menjadi bigint sen, satu kali saja.
+// Harus dijalankan sebelum AutoMigrate supaya nilainya dikalikan 100 dan dibulatkan,
+// bukan sekadar dipotong.
+func migrateMoneyColumns(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for table, columns := range moneyColumns {
+			for _, column := range columns {
+				var dataType string
+				err := tx.Raw(`SELECT data_type FROM information_schema.columns
+					WHERE table_schema = CURRENT_SCHEMA() AND table_name = ? AND column_name = ?`,
+					table, column).Scan(&dataType).Error
+				if err != nil {
+					return err
+				}
+				if dataType != "double precision" && dataType != "numeric" && dataType != "real" {
+					continue
+				}
+
+				sql := fmt.Sprintf(`ALTER TABLE %q ALTER COLUMN %q TYPE bigint USING ROUND(%q::numeric * 100)::bigint`,
+					table, column, column)
+				if err := tx.Exec(sql).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// migrateInvoiceStatuses mengubah invoice lama (status diketik manual) ke status
+// lifecycle. Invoice lama dianggap sudah terkirim; invoice berstatus "Lunas"
+// dibuatkan satu pembayaran sebesar total invoice supaya saldonya tetap nol.
+// Invoice yang lunas karena credit note memang tidak punya pembayaran, jadi
+// tidak ikut dibuatkan.
+func migrateInvoiceStatuses(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO invoice_payments (invoice_id, amount, payment_date, method, reference, created_at, updated_at)
+			SELECT i.id, i.amount, i.issue_date, 'Migrasi', 'Status Lunas sebelum pencatatan pembayaran', NOW(), NOW()
+			FROM invoices i
+			WHERE i.status = ? AND i.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM invoice_payments p WHERE p.invoice_id = i.id)
+			AND NOT EXISTS (SELECT 1 FROM credit_note_allocations a WHERE a.invoice_id = i.id)`,
+			models.StatusPaid).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			UPDATE invoices SET
+				amount_paid = p.paid,
+				balance = invoices.amount - p.paid,
+				status = CASE
+					WHEN p.paid <= 0 THEN ?
+					WHEN invoices.amount - p.paid > 0 THEN ?
+					ELSE ?
+				END
+			FROM (
+				SELECT i.id, COALESCE(SUM(ip.amount), 0) AS paid
+				FROM invoices i
+				LEFT JOIN invoice_payments ip ON ip.invoice_id = i.id
+				GROUP BY i.id
+			) p
+			WHERE p.id = invoices.id AND (invoices.status IS NULL OR invoices.status NOT IN ?)`,
+			models.StatusSent, models.StatusPartiallyPaid, models.StatusPaid, models.InvoiceStatuses).Error
+	})
+}
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+// runOverdueInvoices menandai invoice yang lewat jatuh tempo menurut zona waktu
+// masing-masing user, dan melepas tanda jika jatuh temponya dimundurkan.
+// Update-nya idempoten sehingga aman dijalankan beberapa instance sekaligus.
+func runOverdueInvoices() error {
+	var userIDs []uint
+	if err := database.DB.Model(&models.Invoice{}).
+		Where("status IN ? OR is_overdue = ?", models.PayableStatuses, true).
+		Distinct().
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		setting, err := services.LoadUserSetting(database.DB, userID)
+		if err != nil {
+			return err
+		}
+		today := services.UserToday(setting, now).Format("2006-01-02")
+
+		marked := database.DB.Model(&models.Invoice{}).
+			Where("user_id = ? AND status IN ? AND is_overdue = ?", userID, models.PayableStatuses, false).
+			Where("due_date <> '' AND due_date < ?", today).
+			Update("is_overdue", true)
+		if marked.Error != nil {
+			return marked.Error
+		}
+
+		cleared := database.DB.Model(&models.Invoice{}).
+			Where("user_id = ? AND is_overdue = ?", userID, true).
+			Where("status NOT IN ? OR due_date >= ?", models.PayableStatuses, today).
+			Update("is_overdue", false)
+		if cleared.Error != nil {
+			return cleared.Error
+		}
+
+		if marked.RowsAffected > 0 {
+			log.Printf("⏰ %d invoice user #%d ditandai overdue", marked.RowsAffected, userID)
+		}
+	}
+	return nil
+}
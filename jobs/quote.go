@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+)
+
+// runExpiredQuotes menandai quote terkirim yang lewat masa berlakunya menurut
+// zona waktu masing-masing user. Update-nya idempoten seperti job overdue.
+func runExpiredQuotes() error {
+	var userIDs []uint
+	if err := database.DB.Model(&models.Quote{}).
+		Where("status = ?", models.QuoteStatusSent).
+		Distinct().
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		setting, err := services.LoadUserSetting(database.DB, userID)
+		if err != nil {
+			return err
+		}
+		today := services.UserToday(setting, now).Format("2006-01-02")
+
+		expired := database.DB.Model(&models.Quote{}).
+			Where("user_id = ? AND status = ? AND invoice_id IS NULL", userID, models.QuoteStatusSent).
+			Where("expiry_date <> '' AND expiry_date < ?", today).
+			Update("status", models.QuoteStatusExpired)
+		if expired.Error != nil {
+			return expired.Error
+		}
+
+		if expired.RowsAffected > 0 {
+			log.Printf("⌛ %d quote user #%d kedaluwarsa", expired.RowsAffected, userID)
+		}
+	}
+	return nil
+}
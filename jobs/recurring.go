@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errNoDueRecurring = errors.New("tidak ada invoice berulang yang jatuh jadwal")
+
+// runRecurringInvoices membuat invoice dari template yang sudah jatuh jadwal.
+// Tanggal hari ini dihitung per user sesuai zona waktu di pengaturannya.
+func runRecurringInvoices() error {
+	var userIDs []uint
+	if err := database.DB.Model(&models.RecurringInvoice{}).
+		Where("active = ? AND next_run_date <> ''", true).
+		Distinct().
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		setting, err := services.LoadUserSetting(database.DB, userID)
+		if err != nil {
+			return err
+		}
+		today := services.UserToday(setting, now).Format("2006-01-02")
+
+		if err := runUserRecurringInvoices(userID, today); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runUserRecurringInvoices memproses jadwal milik satu user. Satu putaran hanya
+// memproses satu jadwal per transaksi dan mengulang sampai habis, jadi jadwal
+// yang terlewat saat server mati ikut dibuat satu per satu dengan tanggal
+// terbit aslinya.
+func runUserRecurringInvoices(userID uint, today string) error {
+	var failed []uint
+
+	for {
+		var recurring models.RecurringInvoice
+		var invoice *models.Invoice
+
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			// SKIP LOCKED: template yang sedang diproses instance lain dilewati
+			query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("user_id = ? AND active = ? AND next_run_date <> '' AND next_run_date <= ?", userID, true, today)
+			if len(failed) > 0 {
+				query = query.Where("id NOT IN ?", failed)
+			}
+
+			if err := query.Order("next_run_date ASC, id ASC").First(&recurring).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errNoDueRecurring
+				}
+				return err
+			}
+
+			if err := tx.Where("recurring_invoice_id = ?", recurring.ID).Find(&recurring.Items).Error; err != nil {
+				return err
+			}
+
+			generated, err := services.GenerateRecurringInvoice(tx, &recurring)
+			if err != nil {
+				return err
+			}
+			invoice = generated
+			log.Printf("🧾 Invoice %s dibuat dari invoice berulang #%d", invoice.Number, recurring.ID)
+			return nil
+		})
+
+		if errors.Is(err, errNoDueRecurring) {
+			return nil
+		}
+		if err == nil && recurring.AutoSend {
+			// Email dikirim setelah commit, kegagalan cukup tercatat di log email invoice
+			if _, err := services.EmailInvoice(database.DB, invoice, 0); err != nil {
+				log.Printf("❌ Gagal mengirim email invoice %s: %v", invoice.Number, err)
+			}
+		}
+		if err != nil {
+			if recurring.ID == 0 {
+				return err
+			}
+
+			// Simpan error di template lalu lanjut ke template lain, dicoba lagi di putaran berikutnya
+			failed = append(failed, recurring.ID)
+			log.Printf("❌ Gagal membuat invoice berulang #%d: %v", recurring.ID, err)
+			database.DB.Model(&models.RecurringInvoice{}).
+				Where("id = ?", recurring.ID).
+				Update("last_error", err.Error())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/database"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// runPaymentReminders mengirim pengingat pembayaran sesuai aturan tiap user.
+// Pengingat diklaim di transaksi per invoice dengan kunci baris, jadi pembayaran
+// yang masuk bersamaan menghentikan pengingat untuk invoice itu. Email-nya baru
+// dikirim setelah kunci dilepas supaya server SMTP yang lambat tidak menahan
+// pembayaran atau perubahan invoice.
+func runPaymentReminders() error {
+	var rules []models.ReminderRule
+	if err := database.DB.Where("active = ?", true).Order("user_id ASC, offset_days ASC").Find(&rules).Error; err != nil {
+		return err
+	}
+
+	rulesByUser := make(map[uint][]models.ReminderRule)
+	for _, rule := range rules {
+		rulesByUser[rule.UserID] = append(rulesByUser[rule.UserID], rule)
+	}
+
+	now := time.Now()
+	for userID, userRules := range rulesByUser {
+		setting, err := services.LoadUserSetting(database.DB, userID)
+		if err != nil {
+			return err
+		}
+		today := services.UserToday(setting, now)
+
+		var invoiceIDs []uint
+		if err := database.DB.Model(&models.Invoice{}).
+			Where("user_id = ? AND status IN ? AND due_date <> ''", userID, models.PayableStatuses).
+			Pluck("id", &invoiceIDs).Error; err != nil {
+			return err
+		}
+
+		for _, invoiceID := range invoiceIDs {
+			var invoice models.Invoice
+			var prepared *services.PreparedReminder
+			err := database.DB.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+					Where("id = ? AND status IN ?", invoiceID, models.PayableStatuses).
+					First(&invoice).Error; err != nil {
+					// Sudah lunas atau sedang diproses instance lain
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil
+					}
+					return err
+				}
+
+				var err error
+				prepared, err = services.ClaimDueReminder(tx, &invoice, userRules, today)
+				if err != nil {
+					// Percobaan yang gagal sudah tercatat, transaksinya tetap di-commit
+					log.Printf("❌ Gagal menyiapkan pengingat invoice %s: %v", invoice.Number, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if prepared == nil {
+				continue
+			}
+
+			if err := services.DeliverReminder(database.DB, prepared); err != nil {
+				log.Printf("❌ Gagal mengirim pengingat invoice %s: %v", invoice.Number, err)
+				continue
+			}
+			log.Printf("📧 Pengingat invoice %s dikirim ke %s", invoice.Number, prepared.Reminder.Recipient)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// Start menjalankan job latar belakang di dalam proses server. Setiap job aman
+// dijalankan di beberapa instance sekaligus karena memakai kunci baris database.
+func Start() {
+	go every(15*time.Minute, "invoice berulang", runRecurringInvoices)
+	go every(time.Hour, "invoice overdue", runOverdueInvoices)
+	go every(time.Hour, "pengingat pembayaran", runPaymentReminders)
+	go every(time.Hour, "quote kedaluwarsa", runExpiredQuotes)
+}
+
+// every menjalankan job sekali saat start lalu berulang setiap interval
+func every(interval time.Duration, name string, job func() error) {
+	run := func() {
+		if err := job(); err != nil {
+			log.Printf("❌ Job %s gagal: %v", name, err)
+		}
+	}
+
+	run()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		run()
+	}
+}
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sholllll662/invoice-backend/utils"
+)
+
+// ClientAuthMiddleware memvalidasi token portal client. Token login User
+// ditolak di sini, begitu juga sebaliknya di AuthMiddleware.
+func ClientAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Ambil header authorization
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
+			c.Abort()
+			return
+		}
+
+		//pastikan formatnya "bearer <token>"
+		tokenParts := strings.Split(authHeader, " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
+			c.Abort()
+			return
+		}
+
+		claims, err := utils.ValidateClientToken(tokenParts[1], utils.ClientPortalAudience)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		// Simpan data akun client ke context
+		c.Set("clientAccountID", claims.AccountID)
+		c.Set("clientID", claims.ClientID)
+
+		c.Next()
+	}
+}
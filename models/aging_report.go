@@ -0,0 +1,54 @@
+package models
+
+// AgingBuckets mengelompokkan sisa tagihan berdasarkan hari lewat jatuh tempo
+type AgingBuckets struct {
+	Current    Money `json:"current"` // belum jatuh tempo
+	Days1To30  Money `json:"days_1_30"`
+	Days31To60 Money `json:"days_31_60"`
+	Days61To90 Money `json:"days_61_90"`
+	Over90     Money `json:"days_over_90"`
+	Total      Money `json:"total"`
+}
+
+// Add menambahkan sisa tagihan ke kelompok yang sesuai
+func (b *AgingBuckets) Add(daysPastDue int, amount Money) {
+	switch {
+	case daysPastDue <= 0:
+		b.Current += amount
+	case daysPastDue <= 30:
+		b.Days1To30 += amount
+	case daysPastDue <= 60:
+		b.Days31To60 += amount
+	case daysPastDue <= 90:
+		b.Days61To90 += amount
+	default:
+		b.Over90 += amount
+	}
+	b.Total += amount
+}
+
+type AgingInvoice struct {
+	InvoiceID   uint   `json:"invoice_id"`
+	Number      string `json:"invoice_number"`
+	IssueDate   string `json:"issue_date"`
+	DueDate     string `json:"due_date"`
+	DaysPastDue int    `json:"days_past_due"` // 0 jika belum jatuh tempo
+	Currency    string `json:"currency"`
+	Balance     Money  `json:"balance"`      // dalam mata uang invoice
+	BaseBalance Money  `json:"base_balance"` // dalam mata uang dasar
+}
+
+type AgingClient struct {
+	ClientID uint           `json:"client_id"`
+	Name     string         `json:"name"`
+	Buckets  AgingBuckets   `json:"buckets"`
+	Invoices []AgingInvoice `json:"invoices"`
+}
+
+type AgingReport struct {
+	AsOf          string        `json:"as_of"`
+	BaseCurrency  string        `json:"base_currency"`
+	Clients       []AgingClient `json:"clients"`
+	Totals        AgingBuckets  `json:"totals"`
+	ExcludedCount int64         `json:"excluded_count"` // invoice dengan mata uang dasar lama
+}
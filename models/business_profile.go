@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// BusinessProfile adalah data usaha user yang dicetak sebagai penerbit di PDF
+type BusinessProfile struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	UserID            uint      `json:"user_id" gorm:"uniqueIndex"`
+	LegalName         string    `json:"legal_name"`
+	Address           string    `json:"address" gorm:"type:text"`
+	TaxID             string    `json:"tax_id"` // NPWP
+	Phone             string    `json:"phone"`
+	Email             string    `json:"email"`
+	BankName          string    `json:"bank_name"`
+	BankAccountName   string    `json:"bank_account_name"`
+	BankAccountNumber string    `json:"bank_account_number"`
+	FooterText        string    `json:"footer_text" gorm:"type:text"`
+	Logo              []byte    `json:"-"`
+	LogoContentType   string    `json:"logo_content_type"` // image/png atau image/jpeg
+	HasLogo           bool      `json:"has_logo" gorm:"-"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ApplyUserDefaults memakai nama dan email akun jika profil belum diisi
+func (p *BusinessProfile) ApplyUserDefaults(user User) {
+	if p.LegalName == "" {
+		p.LegalName = user.Name
+	}
+	if p.Email == "" {
+		p.Email = user.Email
+	}
+}
+
+// HasBankAccount menandakan rekening pembayaran sudah diisi
+func (p *BusinessProfile) HasBankAccount() bool {
+	return p.BankName != "" || p.BankAccountNumber != ""
+}
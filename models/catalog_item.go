@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CatalogItem adalah produk atau jasa yang sering ditagihkan. Item invoice yang
+// merujuk ke katalog tetap menyimpan salinan nama dan harga saat ditagihkan.
+type CatalogItem struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"index;uniqueIndex:idx_catalog_items_user_sku,where:sku <> '' AND deleted_at IS NULL"`
+	SKU         string         `json:"sku" gorm:"uniqueIndex:idx_catalog_items_user_sku,expression:LOWER(sku)"` // opsional, unik per user tanpa membedakan huruf besar
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	UnitPrice   Money          `json:"unit_price"`
+	Unit        string         `json:"unit"`        // "jam", "pcs", "bulan", dll.
+	TaxRateID   *uint          `json:"tax_rate_id"` // pajak default item
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
@@ -12,6 +12,7 @@ type Client struct {
 	Nama      string         `json:"name"`
 	Email     string         `json:"email"`
 	NoTlp     string         `json:"no_tlp"`
+	Currency  string         `json:"currency" gorm:"size:3"` // mata uang default invoice, kosong = mata uang dasar user
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
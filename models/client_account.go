@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// ClientAccount adalah login portal untuk satu client. Terpisah dari User:
+// client hanya bisa melihat invoice miliknya sendiri dari vendor yang mengundang.
+type ClientAccount struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"index"` // vendor pemilik client
+	ClientID        uint       `json:"client_id" gorm:"uniqueIndex"`
+	Email           string     `json:"email" gorm:"index"`
+	Password        string     `json:"-"`
+	InviteNonce     string     `json:"-" gorm:"size:64"` // kosong setelah undangan dipakai
+	InviteExpiresAt *time.Time `json:"invite_expires_at"`
+	ActivatedAt     *time.Time `json:"activated_at"`
+	LastLoginAt     *time.Time `json:"last_login_at"`
+	DisabledAt      *time.Time `json:"disabled_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// CanLogin menandakan akun sudah diaktifkan dan aksesnya tidak dicabut
+func (a ClientAccount) CanLogin() bool {
+	return a.ActivatedAt != nil && a.DisabledAt == nil && a.Password != ""
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status credit note: Terbit selama masih ada sisa kredit, Selesai setelah
+// seluruhnya dipakai atau dikembalikan
+const (
+	CreditNoteStatusOpen   = "Terbit"
+	CreditNoteStatusClosed = "Selesai"
+	CreditNoteStatusVoid   = "Void"
+)
+
+// CreditNote mengoreksi invoice tanpa mengubah invoice aslinya. Kreditnya bisa
+// dipakai untuk mengurangi tagihan invoice client yang sama atau dikembalikan (refund).
+type CreditNote struct {
+	ID             uint                   `json:"id" gorm:"primaryKey"`
+	UserID         uint                   `json:"user_id" gorm:"uniqueIndex:idx_credit_notes_user_number,where:number <> ''"`
+	ClientID       uint                   `json:"client_id" gorm:"index"`
+	InvoiceID      uint                   `json:"invoice_id" gorm:"index"` // invoice asli yang dikoreksi
+	Number         string                 `json:"credit_note_number" gorm:"uniqueIndex:idx_credit_notes_user_number"`
+	IssueDate      string                 `json:"issue_date"`
+	Reason         string                 `json:"reason"`
+	Status         string                 `json:"status"`
+	Currency       string                 `json:"currency" gorm:"size:3"` // selalu sama dengan invoice asli
+	ExchangeRate   float64                `json:"exchange_rate"`
+	BaseCurrency   string                 `json:"base_currency" gorm:"size:3"`
+	Subtotal       Money                  `json:"subtotal"`
+	TaxTotal       Money                  `json:"tax_total"`
+	Amount         Money                  `json:"amount"`
+	BaseAmount     Money                  `json:"base_amount"`
+	AmountApplied  Money                  `json:"amount_applied"`  // dipakai ke invoice
+	AmountRefunded Money                  `json:"amount_refunded"` // dikembalikan ke client
+	Balance        Money                  `json:"balance"`         // sisa kredit
+	Items          []CreditNoteItem       `json:"items" gorm:"foreignKey:CreditNoteID"`
+	Allocations    []CreditNoteAllocation `json:"allocations,omitempty" gorm:"foreignKey:CreditNoteID"`
+	Refunds        []CreditNoteRefund     `json:"refunds,omitempty" gorm:"foreignKey:CreditNoteID"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt         `json:"-" gorm:"index"`
+}
+
+type CreditNoteItem struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CreditNoteID  uint      `json:"credit_note_id" gorm:"index"`
+	InvoiceItemID *uint     `json:"invoice_item_id" gorm:"index"` // baris invoice asli, kosong untuk baris bebas
+	ItemName      string    `json:"item_name"`
+	Quantity      int       `json:"quantity"`
+	UnitPrice     Money     `json:"unit_price"`
+	TotalPrice    Money     `json:"total_price"`
+	TaxableAmount Money     `json:"taxable_amount"`
+	TaxAmount     Money     `json:"tax_amount"`
+	Taxes         []ItemTax `json:"taxes" gorm:"serializer:json;type:jsonb"`
+	TaxRateIDs    []uint    `json:"tax_rate_ids,omitempty" gorm:"-"` // hanya untuk request
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CreditNoteAllocation adalah bagian kredit yang dipakai untuk mengurangi tagihan invoice
+type CreditNoteAllocation struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CreditNoteID uint      `json:"credit_note_id" gorm:"index"`
+	InvoiceID    uint      `json:"invoice_id" gorm:"index"`
+	Amount       Money     `json:"amount"`
+	UserID       uint      `json:"user_id"` // user yang memakai kredit, 0 jika oleh sistem
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CreditNoteRefund adalah sisa kredit yang dikembalikan ke client
+type CreditNoteRefund struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CreditNoteID uint      `json:"credit_note_id" gorm:"index"`
+	Amount       Money     `json:"amount"`
+	RefundDate   string    `json:"refund_date"`
+	Method       string    `json:"method"`
+	Reference    string    `json:"reference"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// asInvoice menyusun invoice sementara supaya perhitungan pajak credit note
+// sama persis dengan invoice
+func (n *CreditNote) asInvoice() Invoice {
+	calc := Invoice{ExchangeRate: n.ExchangeRate, Items: make([]InvoiceItem, len(n.Items))}
+	for i, item := range n.Items {
+		calc.Items[i] = InvoiceItem{
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Taxes:     append([]ItemTax(nil), item.Taxes...),
+		}
+	}
+	return calc
+}
+
+// CalculateTotals menghitung total per item dan total credit note
+func (n *CreditNote) CalculateTotals() {
+	calc := n.asInvoice()
+	calc.CalculateTotals()
+
+	for i := range n.Items {
+		n.Items[i].TotalPrice = calc.Items[i].TotalPrice
+		n.Items[i].TaxableAmount = calc.Items[i].TaxableAmount
+		n.Items[i].TaxAmount = calc.Items[i].TaxAmount
+		n.Items[i].Taxes = calc.Items[i].Taxes
+	}
+	n.Subtotal = calc.Subtotal
+	n.TaxTotal = calc.TaxTotal
+	n.Amount = calc.Amount
+	n.BaseAmount = calc.BaseAmount
+}
+
+// TaxSummaries mengelompokkan pajak seluruh item per tarif
+func (n *CreditNote) TaxSummaries() []TaxSummary {
+	calc := n.asInvoice()
+	return calc.TaxSummaries()
+}
+
+// UpdateBalance menghitung sisa kredit lalu menyesuaikan status
+func (n *CreditNote) UpdateBalance(applied, refunded Money) {
+	n.AmountApplied = applied
+	n.AmountRefunded = refunded
+	n.Balance = n.Amount - applied - refunded
+
+	if n.Status == CreditNoteStatusVoid {
+		return
+	}
+	n.Status = CreditNoteStatusOpen
+	if n.Balance <= 0 {
+		n.Status = CreditNoteStatusClosed
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Jenis dokumen yang memiliki nomor urut sendiri
+const (
+	DocTypeInvoice    = "invoice"
+	DocTypeCreditNote = "credit_note"
+	DocTypeQuote      = "quote"
+)
+
+// DocumentSequence menyimpan nomor urut terakhir per user, jenis dokumen dan periode
+type DocumentSequence struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"uniqueIndex:idx_document_sequence"`
+	DocType    string    `json:"doc_type" gorm:"uniqueIndex:idx_document_sequence"`
+	Period     string    `json:"period" gorm:"uniqueIndex:idx_document_sequence"` // "2025" atau "2025-03"
+	LastNumber int       `json:"last_number"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
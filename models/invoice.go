@@ -7,16 +7,81 @@ import (
 )
 
 type Invoice struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	UserID    uint           `json:"user_id"`
-	ClientID  uint           `json:"client_id"`
-	IssueDate string         `json:"issue_date" binding:"required"`
-	DueDate   string         `json:"due_date" binding:"required"`
-	Amount    float64        `json:"amount"`
-	Status    string         `json:"status"` // "Pending", "Lunas", dll.
-	Note      string         `json:"note"`
-	Items     []InvoiceItem  `json:"items" gorm:"foreignKey:InvoiceID"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // optional, soft delete
+	ID                 uint                   `json:"id" gorm:"primaryKey"`
+	UserID             uint                   `json:"user_id" gorm:"uniqueIndex:idx_invoices_user_number,where:number <> ''"`
+	ClientID           uint                   `json:"client_id"`
+	Number             string                 `json:"invoice_number" gorm:"uniqueIndex:idx_invoices_user_number"` // unik per user
+	RecurringInvoiceID *uint                  `json:"recurring_invoice_id,omitempty" gorm:"index"`                // template asal jika dibuat otomatis
+	QuoteID            *uint                  `json:"quote_id,omitempty" gorm:"index"`                            // quote asal jika dibuat dari konversi
+	IssueDate          string                 `json:"issue_date" binding:"required"`
+	DueDate            string                 `json:"due_date" binding:"required"`
+	Subtotal           Money                  `json:"subtotal"` // jumlah harga item setelah diskon baris
+	DiscountType       string                 `json:"discount_type"`
+	DiscountValue      Money                  `json:"discount_value"`  // persen (10 = 10%) atau nominal
+	DiscountAmount     Money                  `json:"discount_amount"` // diskon tingkat invoice
+	TaxTotal           Money                  `json:"tax_total"`       // jumlah seluruh pajak
+	Amount             Money                  `json:"amount"`
+	AmountPaid         Money                  `json:"amount_paid"`
+	CreditedAmount     Money                  `json:"credited_amount" gorm:"default:0"` // dari credit note
+	Balance            Money                  `json:"balance"`                          // sisa tagihan = Amount - AmountPaid - CreditedAmount
+	Status             string                 `json:"status"`
+	IsOverdue          bool                   `json:"is_overdue" gorm:"index"` // ditandai job overdue
+	DaysOverdue        int                    `json:"days_overdue" gorm:"-"`   // dihitung saat response
+	Currency           string                 `json:"currency" gorm:"size:3;default:IDR"`
+	ExchangeRate       float64                `json:"exchange_rate" gorm:"default:1"` // nilai 1 unit Currency dalam BaseCurrency saat terbit
+	BaseCurrency       string                 `json:"base_currency" gorm:"size:3;default:IDR"`
+	BaseAmount         Money                  `json:"base_amount"` // Amount dalam BaseCurrency
+	Note               string                 `json:"note"`
+	PDFTemplate        string                 `json:"pdf_template"` // key template PDF, kosong = pengaturan user
+	Items              []InvoiceItem          `json:"items" gorm:"foreignKey:InvoiceID"`
+	Payments           []InvoicePayment       `json:"payments,omitempty" gorm:"foreignKey:InvoiceID"`
+	History            []InvoiceStatusHistory `json:"status_history,omitempty" gorm:"foreignKey:InvoiceID"`
+	Reminders          []InvoiceReminder      `json:"reminders,omitempty" gorm:"foreignKey:InvoiceID"`
+	Credits            []CreditNoteAllocation `json:"credits,omitempty" gorm:"foreignKey:InvoiceID"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt         `json:"-" gorm:"index"` // optional, soft delete
+}
+
+// UpdateBalance menghitung ulang sisa tagihan berdasarkan total pembayaran
+// dan kredit yang sudah dipakai
+func (i *Invoice) UpdateBalance(paid Money) {
+	i.AmountPaid = paid
+	i.Balance = i.Amount - paid - i.CreditedAmount
+}
+
+// SetDaysOverdue menghitung jumlah hari lewat jatuh tempo per tanggal today
+func (i *Invoice) SetDaysOverdue(today time.Time) {
+	i.DaysOverdue = 0
+	if !i.IsOverdue {
+		return
+	}
+
+	due, err := time.Parse("2006-01-02", i.DueDate)
+	if err != nil || !today.After(due) {
+		return
+	}
+	i.DaysOverdue = int(today.Sub(due).Hours() / 24)
+}
+
+// PaymentStatus mengembalikan status yang sesuai dengan saldo invoice saat ini
+// Invoice yang seluruhnya tertutup credit note juga dianggap lunas.
+func (i *Invoice) PaymentStatus() string {
+	switch {
+	case i.Balance <= 0 && i.AmountPaid+i.CreditedAmount > 0:
+		return StatusPaid
+	case i.AmountPaid > 0:
+		return StatusPartiallyPaid
+	default:
+		return StatusSent
+	}
+}
+
+// NetUnitPrice adalah harga satuan setelah diskon baris dan diskon invoice,
+// dipakai sebagai harga default baris credit note
+func (item InvoiceItem) NetUnitPrice() Money {
+	if item.Quantity == 0 {
+		return 0
+	}
+	return item.netPrice().Ratio(1, Money(item.Quantity))
 }
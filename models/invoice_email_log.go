@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Status percobaan pengiriman email invoice
+const (
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+	EmailStatusPending = "pending" // pengingat sudah diklaim, email belum selesai dikirim
+)
+
+type InvoiceEmailLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID uint      `json:"invoice_id" gorm:"index"`
+	UserID    uint      `json:"user_id"` // user yang mengirim, 0 jika oleh sistem
+	Recipient string    `json:"recipient"`
+	Subject   string    `json:"subject"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
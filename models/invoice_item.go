@@ -1,16 +1,60 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
 type InvoiceItem struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	InvoiceID  uint      `json:"invoice_id"`
-	ItemName   string    `json:"item_name"`
-	Quantity   int       `json:"quantity"`
-	UnitPrice  float64   `json:"unit_price"`
-	TotalPrice float64   `json:"total_price"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID       uint      `json:"invoice_id"`
+	CatalogItemID   *uint     `json:"catalog_item_id,omitempty" gorm:"index"` // nama dan harga tetap salinan
+	ItemName        string    `json:"item_name"`
+	Quantity        int       `json:"quantity"`
+	UnitPrice       Money     `json:"unit_price"`
+	TotalPrice      Money     `json:"total_price"` // Quantity * UnitPrice
+	DiscountType    string    `json:"discount_type"`
+	DiscountValue   Money     `json:"discount_value"`   // persen (10 = 10%) atau nominal
+	DiscountAmount  Money     `json:"discount_amount"`  // diskon baris
+	InvoiceDiscount Money     `json:"invoice_discount"` // bagian diskon invoice untuk baris ini
+	TaxableAmount   Money     `json:"taxable_amount"`   // dasar pengenaan pajak
+	TaxAmount       Money     `json:"tax_amount"`
+	Taxes           []ItemTax `json:"taxes" gorm:"serializer:json;type:jsonb"`
+	TaxRateIDs      []uint    `json:"tax_rate_ids,omitempty" gorm:"-"` // hanya untuk request
+	// UnitPriceOmitted bernilai true jika request tidak mengirim unit_price, jadi
+	// harga diambil dari katalog. Harga 0 yang dikirim tetap dianggap gratis.
+	UnitPriceOmitted bool      `json:"-" gorm:"-"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (item *InvoiceItem) UnmarshalJSON(data []byte) error {
+	type plain InvoiceItem
+	if err := json.Unmarshal(data, (*plain)(item)); err != nil {
+		return err
+	}
+	sent, err := jsonFieldSent(data, "unit_price")
+	item.UnitPriceOmitted = !sent
+	return err
+}
+
+// jsonFieldSent menandakan objek JSON berisi field tersebut dengan nilai selain null
+func jsonFieldSent(data []byte, field string) (bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false, err
+	}
+	value, ok := fields[field]
+	return ok && string(value) != "null", nil
+}
+
+// ItemTax adalah salinan tarif pajak saat invoice dibuat, supaya total lama
+// tidak berubah ketika tarif pajak diedit
+type ItemTax struct {
+	TaxRateID uint    `json:"tax_rate_id"`
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	Compound  bool    `json:"compound"`
+	Amount    Money   `json:"amount"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+type InvoicePayment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID   uint      `json:"invoice_id" gorm:"index"`
+	Amount      Money     `json:"amount"`
+	PaymentDate string    `json:"payment_date"`
+	Method      string    `json:"method"`    // "Transfer", "Tunai", dll.
+	Reference   string    `json:"reference"` // no. referensi / bukti transfer
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// InvoiceReminder mencatat setiap pengingat yang dikirim untuk sebuah invoice
+type InvoiceReminder struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID      uint      `json:"invoice_id" gorm:"index"`
+	ReminderRuleID uint      `json:"reminder_rule_id" gorm:"index"`
+	OffsetDays     int       `json:"offset_days"` // salinan dari aturan saat dikirim
+	Recipient      string    `json:"recipient"`
+	Subject        string    `json:"subject"`
+	Status         string    `json:"status"` // EmailStatusPending, EmailStatusSent atau EmailStatusFailed
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
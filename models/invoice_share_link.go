@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// InvoiceShareLink adalah tautan publik sebuah invoice. Token-nya tidak disimpan,
+// cukup Nonce yang ikut ditandatangani sehingga tautan bisa dicabut satu per satu.
+type InvoiceShareLink struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	InvoiceID     uint       `json:"invoice_id" gorm:"index"`
+	UserID        uint       `json:"user_id"`
+	Nonce         string     `json:"-" gorm:"size:64"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	FirstViewedAt *time.Time `json:"first_viewed_at"`
+	LastViewedAt  *time.Time `json:"last_viewed_at"`
+	ViewCount     int        `json:"view_count"`
+	Token         string     `json:"token,omitempty" gorm:"-"`
+	URL           string     `json:"url,omitempty" gorm:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsActive menandakan tautan belum dicabut dan belum kedaluwarsa
+func (l InvoiceShareLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
@@ -0,0 +1,54 @@
+package models
+
+// Status invoice sesuai lifecycle:
+// Draft → Terkirim → Lunas Sebagian → Lunas, ditambah Void dan Dibatalkan
+const (
+	StatusDraft         = "Draft"
+	StatusSent          = "Terkirim"
+	StatusPartiallyPaid = "Lunas Sebagian"
+	StatusPaid          = "Lunas"
+	StatusVoid          = "Void"       // invoice yang sudah terkirim lalu dibatalkan
+	StatusCancelled     = "Dibatalkan" // draft yang tidak jadi dikirim
+)
+
+var InvoiceStatuses = []string{
+	StatusDraft, StatusSent, StatusPartiallyPaid, StatusPaid, StatusVoid, StatusCancelled,
+}
+
+// invoiceTransitions berisi perpindahan status yang diizinkan.
+// Perpindahan mundur antar status pembayaran terjadi saat pembayaran dihapus.
+var invoiceTransitions = map[string][]string{
+	StatusDraft:         {StatusSent, StatusCancelled},
+	StatusSent:          {StatusPartiallyPaid, StatusPaid, StatusVoid},
+	StatusPartiallyPaid: {StatusSent, StatusPaid},
+	StatusPaid:          {StatusSent, StatusPartiallyPaid},
+	StatusVoid:          {},
+	StatusCancelled:     {},
+}
+
+// AllowedTransitions mengembalikan status tujuan yang sah dari status saat ini
+func AllowedTransitions(from string) []string {
+	return invoiceTransitions[from]
+}
+
+func CanTransition(from, to string) bool {
+	for _, s := range invoiceTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable menandakan invoice masih boleh diubah isinya
+func IsEditable(status string) bool {
+	return status == StatusDraft || status == StatusSent
+}
+
+// PayableStatuses adalah status invoice yang masih menunggu pembayaran
+var PayableStatuses = []string{StatusSent, StatusPartiallyPaid}
+
+// IsPayable menandakan invoice bisa menerima pembayaran
+func IsPayable(status string) bool {
+	return status == StatusSent || status == StatusPartiallyPaid
+}
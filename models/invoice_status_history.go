@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+type InvoiceStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID  uint      `json:"invoice_id" gorm:"index"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	UserID     uint      `json:"user_id"` // user yang melakukan perubahan, 0 jika oleh sistem
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tata letak PDF invoice
+const (
+	LayoutClassic = "classic" // tabel berlatar abu-abu seperti PDF lama
+	LayoutModern  = "modern"  // judul dan header tabel berwarna aksen
+	LayoutCompact = "compact" // huruf kecil, muat banyak item per halaman
+)
+
+// Posisi logo profil usaha
+const (
+	LogoLeft  = "left"
+	LogoRight = "right"
+	LogoNone  = "none"
+)
+
+// Kolom tabel item yang bisa ditampilkan
+const (
+	ColumnDescription = "description"
+	ColumnUnitPrice   = "unit_price"
+	ColumnQuantity    = "quantity"
+	ColumnDiscount    = "discount"
+	ColumnTax         = "tax"
+	ColumnTotal       = "total"
+)
+
+// InvoiceColumns adalah seluruh kolom dengan urutan cetaknya
+var InvoiceColumns = []string{ColumnDescription, ColumnUnitPrice, ColumnQuantity, ColumnDiscount, ColumnTax, ColumnTotal}
+
+// DefaultInvoiceTemplate dipakai jika invoice dan pengaturan user tidak memilih template
+const DefaultInvoiceTemplate = LayoutClassic
+
+var accentColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// InvoiceTemplate mengatur tampilan PDF invoice. Template bawaan tidak
+// disimpan di database dan dirujuk dengan Key seperti "classic", template
+// buatan user dirujuk dengan ID-nya.
+type InvoiceTemplate struct {
+	ID           uint           `json:"id,omitempty" gorm:"primaryKey"`
+	UserID       uint           `json:"user_id,omitempty" gorm:"index"`
+	Key          string         `json:"key" gorm:"-"`
+	BuiltIn      bool           `json:"built_in" gorm:"-"`
+	Name         string         `json:"name"`
+	Layout       string         `json:"layout"`
+	AccentColor  string         `json:"accent_color" gorm:"size:7"` // format #RRGGBB
+	LogoPosition string         `json:"logo_position"`
+	Columns      []string       `json:"columns" gorm:"serializer:json;type:jsonb"`
+	FooterText   string         `json:"footer_text" gorm:"type:text"` // menggantikan ucapan terima kasih bawaan
+	TermsText    string         `json:"terms_text" gorm:"type:text"`  // syarat dan ketentuan di bawah total
+	CreatedAt    time.Time      `json:"created_at,omitzero"`
+	UpdatedAt    time.Time      `json:"updated_at,omitzero"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+var builtinInvoiceTemplates = []InvoiceTemplate{
+	{
+		Key:          LayoutClassic,
+		Name:         "Klasik",
+		Layout:       LayoutClassic,
+		AccentColor:  "#000000",
+		LogoPosition: LogoRight,
+		Columns:      []string{ColumnDescription, ColumnUnitPrice, ColumnQuantity, ColumnTotal},
+		FooterText:   "TERIMAKASIH ATAS\nPEMBELIAN ANDA",
+	},
+	{
+		Key:          LayoutModern,
+		Name:         "Modern",
+		Layout:       LayoutModern,
+		AccentColor:  "#1F4E79",
+		LogoPosition: LogoLeft,
+		Columns:      []string{ColumnDescription, ColumnUnitPrice, ColumnQuantity, ColumnDiscount, ColumnTax, ColumnTotal},
+		FooterText:   "Terima kasih atas kepercayaan Anda.",
+	},
+	{
+		Key:          LayoutCompact,
+		Name:         "Ringkas",
+		Layout:       LayoutCompact,
+		AccentColor:  "#404040",
+		LogoPosition: LogoNone,
+		Columns:      []string{ColumnDescription, ColumnQuantity, ColumnTotal},
+	},
+}
+
+// BuiltinInvoiceTemplates mengembalikan salinan seluruh template bawaan
+func BuiltinInvoiceTemplates() []InvoiceTemplate {
+	templates := make([]InvoiceTemplate, len(builtinInvoiceTemplates))
+	for i, t := range builtinInvoiceTemplates {
+		t.BuiltIn = true
+		t.Columns = slices.Clone(t.Columns)
+		templates[i] = t
+	}
+	return templates
+}
+
+// BuiltinInvoiceTemplate mencari template bawaan berdasarkan key
+func BuiltinInvoiceTemplate(key string) (InvoiceTemplate, bool) {
+	for _, t := range BuiltinInvoiceTemplates() {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return InvoiceTemplate{}, false
+}
+
+// SetKey mengisi Key template buatan user dari ID-nya
+func (t *InvoiceTemplate) SetKey() {
+	if !t.BuiltIn {
+		t.Key = strconv.FormatUint(uint64(t.ID), 10)
+	}
+}
+
+// Validate memeriksa pilihan template dan mengurutkan kolom sesuai urutan cetak
+func (t *InvoiceTemplate) Validate() error {
+	switch t.Layout {
+	case LayoutClassic, LayoutModern, LayoutCompact:
+	default:
+		return fmt.Errorf("layout harus %s, %s atau %s", LayoutClassic, LayoutModern, LayoutCompact)
+	}
+
+	switch t.LogoPosition {
+	case "":
+		t.LogoPosition = LogoRight
+	case LogoLeft, LogoRight, LogoNone:
+	default:
+		return fmt.Errorf("logo_position harus %s, %s atau %s", LogoLeft, LogoRight, LogoNone)
+	}
+
+	if t.AccentColor == "" {
+		t.AccentColor = "#000000"
+	}
+	if !accentColorPattern.MatchString(t.AccentColor) {
+		return errors.New("accent_color harus berformat #RRGGBB")
+	}
+
+	for _, column := range t.Columns {
+		if !slices.Contains(InvoiceColumns, column) {
+			return fmt.Errorf("kolom %q tidak dikenal", column)
+		}
+	}
+	if !slices.Contains(t.Columns, ColumnDescription) || !slices.Contains(t.Columns, ColumnTotal) {
+		return errors.New("kolom description dan total wajib ditampilkan")
+	}
+
+	var columns []string
+	for _, column := range InvoiceColumns {
+		if slices.Contains(t.Columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	t.Columns = columns
+	return nil
+}
+
+// HasColumn menandakan kolom ditampilkan di tabel item
+func (t InvoiceTemplate) HasColumn(column string) bool {
+	return slices.Contains(t.Columns, column)
+}
+
+// AccentRGB mengurai AccentColor menjadi komponen merah, hijau dan biru
+func (t InvoiceTemplate) AccentRGB() (int, int, int) {
+	value, err := strconv.ParseUint(t.AccentColor[min(1, len(t.AccentColor)):], 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return int(value >> 16 & 0xFF), int(value >> 8 & 0xFF), int(value & 0xFF)
+}
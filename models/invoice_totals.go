@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Jenis diskon untuk item maupun invoice
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// TaxSummary adalah total pajak per tarif untuk ringkasan di invoice
+type TaxSummary struct {
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	Amount    Money   `json:"amount"`
+}
+
+// discountAmount menghitung nilai diskon dari dasar tertentu, maksimal sebesar dasarnya
+func discountAmount(discountType string, value, base Money) Money {
+	var amount Money
+	switch discountType {
+	case DiscountPercent:
+		amount = base.MulRat(new(big.Rat).Quo(value.rat(), big.NewRat(100, 1)))
+	case DiscountFixed:
+		amount = value
+	}
+	return min(amount, base)
+}
+
+func validateDiscount(discountType string, value Money) error {
+	switch discountType {
+	case "":
+		return nil
+	case DiscountPercent:
+		if value < 0 || value > 100*moneyScale {
+			return errors.New("diskon persen harus di antara 0 dan 100")
+		}
+	case DiscountFixed:
+		if value < 0 {
+			return errors.New("diskon nominal tidak boleh negatif")
+		}
+	default:
+		return errors.New("discount_type harus percent atau fixed")
+	}
+	return nil
+}
+
+// ValidateDiscounts memeriksa jenis dan nilai diskon invoice beserta itemnya
+func (i *Invoice) ValidateDiscounts() error {
+	if err := validateDiscount(i.DiscountType, i.DiscountValue); err != nil {
+		return err
+	}
+	for _, item := range i.Items {
+		if err := validateDiscount(item.DiscountType, item.DiscountValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// netPrice adalah harga baris setelah diskon baris dan bagian diskon invoice
+func (item *InvoiceItem) netPrice() Money {
+	return item.TotalPrice - item.DiscountAmount - item.InvoiceDiscount
+}
+
+// calculateTaxes menghitung pajak satu item dari harga setelah diskon.
+// Aturannya: pajak inclusive dikeluarkan dulu dari harga untuk mendapat dasar
+// pengenaan pajak, pajak biasa dihitung dari dasar tersebut, lalu pajak
+// compound dihitung dari dasar ditambah pajak-pajak sebelumnya sesuai urutan.
+func (item *InvoiceItem) calculateTaxes() {
+	price := item.netPrice()
+
+	// Dasar = harga / (1 + total tarif inclusive)
+	divisor := big.NewRat(1, 1)
+	hasInclusive := false
+	for _, tax := range item.Taxes {
+		if tax.Inclusive {
+			divisor.Add(divisor, rateRat(tax.Rate))
+			hasInclusive = true
+		}
+	}
+
+	item.TaxableAmount = price.MulRat(new(big.Rat).Inv(divisor))
+	item.TaxAmount = 0
+
+	for i := range item.Taxes {
+		tax := &item.Taxes[i]
+		base := item.TaxableAmount
+		if tax.Compound {
+			base += item.TaxAmount
+		}
+		tax.Amount = base.Percent(tax.Rate)
+		item.TaxAmount += tax.Amount
+	}
+
+	// Pajak inclusive sudah ada di harga, selisih pembulatan dibebankan ke dasar
+	if hasInclusive {
+		var inclusiveTax Money
+		for _, tax := range item.Taxes {
+			if tax.Inclusive {
+				inclusiveTax += tax.Amount
+			}
+		}
+		item.TaxableAmount = price - inclusiveTax
+	}
+}
+
+// CalculateTotals menghitung ulang total per item dan ringkasan invoice.
+// Urutannya: diskon baris, diskon invoice (dibagi proporsional ke setiap baris),
+// baru kemudian pajak dari harga setelah diskon.
+// Amount = jumlah dasar pengenaan pajak + seluruh pajak.
+func (i *Invoice) CalculateTotals() {
+	i.Subtotal = 0
+	i.TaxTotal = 0
+	i.Amount = 0
+
+	for idx := range i.Items {
+		item := &i.Items[idx]
+		item.TotalPrice = item.UnitPrice.Mul(item.Quantity)
+		item.DiscountAmount = discountAmount(item.DiscountType, item.DiscountValue, item.TotalPrice)
+		item.InvoiceDiscount = 0
+		i.Subtotal += item.TotalPrice - item.DiscountAmount
+	}
+
+	// Bagi diskon invoice sesuai porsi tiap baris, sisa pembulatan masuk ke baris terakhir
+	i.DiscountAmount = discountAmount(i.DiscountType, i.DiscountValue, i.Subtotal)
+	if i.DiscountAmount > 0 {
+		remaining := i.DiscountAmount
+		last := -1
+		for idx := range i.Items {
+			item := &i.Items[idx]
+			lineTotal := item.TotalPrice - item.DiscountAmount
+			if lineTotal <= 0 {
+				continue
+			}
+			item.InvoiceDiscount = i.DiscountAmount.Ratio(lineTotal, i.Subtotal)
+			remaining -= item.InvoiceDiscount
+			last = idx
+		}
+		if last >= 0 {
+			i.Items[last].InvoiceDiscount += remaining
+		}
+	}
+
+	for idx := range i.Items {
+		item := &i.Items[idx]
+		item.calculateTaxes()
+
+		i.TaxTotal += item.TaxAmount
+		i.Amount += item.TaxableAmount + item.TaxAmount
+	}
+
+	i.BaseAmount = i.Amount.Convert(i.ExchangeRate)
+}
+
+// TaxSummaries mengelompokkan pajak seluruh item per tarif
+func (i *Invoice) TaxSummaries() []TaxSummary {
+	var summaries []TaxSummary
+	index := map[TaxSummary]int{}
+
+	for _, item := range i.Items {
+		for _, tax := range item.Taxes {
+			key := TaxSummary{Name: tax.Name, Rate: tax.Rate, Inclusive: tax.Inclusive}
+			pos, ok := index[key]
+			if !ok {
+				pos = len(summaries)
+				index[key] = pos
+				summaries = append(summaries, key)
+			}
+			summaries[pos].Amount += tax.Amount
+		}
+	}
+	return summaries
+}
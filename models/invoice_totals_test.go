@@ -0,0 +1,178 @@
+package models
+
+import "testing"
+
+func TestCalculateTotals(t *testing.T) {
+	ppn := ItemTax{Name: "PPN", Rate: 11}
+	ppnInclusive := ItemTax{Name: "PPN", Rate: 11, Inclusive: true}
+
+	tests := []struct {
+		name      string
+		invoice   Invoice
+		subtotal  Money
+		discount  Money
+		taxTotal  Money
+		amount    Money
+		taxable   []Money
+		taxAmount []Money
+		shares    []Money // bagian diskon invoice per baris
+	}{
+		{
+			name: "PPN 11% inclusive",
+			invoice: Invoice{Items: []InvoiceItem{
+				{Quantity: 1, UnitPrice: 111_000_00, Taxes: []ItemTax{ppnInclusive}},
+			}},
+			subtotal:  111_000_00,
+			taxTotal:  11_000_00,
+			amount:    111_000_00,
+			taxable:   []Money{100_000_00},
+			taxAmount: []Money{11_000_00},
+		},
+		{
+			// 10000 / 1.11 = 9009.009 -> 9009, pajak 990.99 -> 991, selisih masuk ke dasar
+			name: "PPN 11% inclusive dengan pembulatan",
+			invoice: Invoice{Items: []InvoiceItem{
+				{Quantity: 1, UnitPrice: 100_00, Taxes: []ItemTax{ppnInclusive}},
+			}},
+			subtotal:  100_00,
+			taxTotal:  991,
+			amount:    100_00,
+			taxable:   []Money{9009},
+			taxAmount: []Money{991},
+		},
+		{
+			// PPN 10% dari 1000 = 100, pajak compound 5% dari 1000 + 100 = 55
+			name: "exclusive lalu compound",
+			invoice: Invoice{Items: []InvoiceItem{
+				{Quantity: 2, UnitPrice: 500_00, Taxes: []ItemTax{
+					{Name: "PPN", Rate: 10},
+					{Name: "Pajak daerah", Rate: 5, Compound: true},
+				}},
+			}},
+			subtotal:  1000_00,
+			taxTotal:  155_00,
+			amount:    1155_00,
+			taxable:   []Money{1000_00},
+			taxAmount: []Money{155_00},
+		},
+		{
+			// 100 dibagi tiga baris sama besar: 33.33, 33.33 dan sisa 33.34 di baris terakhir
+			name: "diskon invoice dibagi proporsional dengan sisa di baris terakhir",
+			invoice: Invoice{
+				DiscountType:  DiscountFixed,
+				DiscountValue: 100_00,
+				Items: []InvoiceItem{
+					{Quantity: 1, UnitPrice: 100_00},
+					{Quantity: 1, UnitPrice: 100_00},
+					{Quantity: 1, UnitPrice: 100_00},
+				},
+			},
+			subtotal: 300_00,
+			discount: 100_00,
+			amount:   200_00,
+			taxable:  []Money{6667, 6667, 6666},
+			shares:   []Money{3333, 3333, 3334},
+		},
+		{
+			name: "diskon invoice sebelum pajak",
+			invoice: Invoice{
+				DiscountType:  DiscountPercent,
+				DiscountValue: 10_00,
+				Items: []InvoiceItem{
+					{Quantity: 1, UnitPrice: 1000_00, Taxes: []ItemTax{ppn}},
+				},
+			},
+			subtotal:  1000_00,
+			discount:  100_00,
+			taxTotal:  99_00,
+			amount:    999_00,
+			taxable:   []Money{900_00},
+			taxAmount: []Money{99_00},
+			shares:    []Money{100_00},
+		},
+		{
+			name: "diskon 100%",
+			invoice: Invoice{
+				DiscountType:  DiscountPercent,
+				DiscountValue: 100_00,
+				Items: []InvoiceItem{
+					{Quantity: 3, UnitPrice: 250_00, Taxes: []ItemTax{ppn}},
+					{Quantity: 1, UnitPrice: 99_99, Taxes: []ItemTax{ppnInclusive}},
+				},
+			},
+			subtotal:  849_99,
+			discount:  849_99,
+			amount:    0,
+			taxable:   []Money{0, 0},
+			taxAmount: []Money{0, 0},
+		},
+		{
+			name: "diskon baris 100%",
+			invoice: Invoice{Items: []InvoiceItem{
+				{Quantity: 1, UnitPrice: 500_00, DiscountType: DiscountPercent, DiscountValue: 100_00, Taxes: []ItemTax{ppn}},
+				{Quantity: 1, UnitPrice: 100_00, Taxes: []ItemTax{ppn}},
+			}},
+			subtotal:  100_00,
+			taxTotal:  11_00,
+			amount:    111_00,
+			taxable:   []Money{0, 100_00},
+			taxAmount: []Money{0, 11_00},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := tt.invoice
+			invoice.ExchangeRate = 1
+			invoice.CalculateTotals()
+
+			if invoice.Subtotal != tt.subtotal {
+				t.Errorf("Subtotal = %d, want %d", invoice.Subtotal, tt.subtotal)
+			}
+			if invoice.DiscountAmount != tt.discount {
+				t.Errorf("DiscountAmount = %d, want %d", invoice.DiscountAmount, tt.discount)
+			}
+			if invoice.TaxTotal != tt.taxTotal {
+				t.Errorf("TaxTotal = %d, want %d", invoice.TaxTotal, tt.taxTotal)
+			}
+			if invoice.Amount != tt.amount {
+				t.Errorf("Amount = %d, want %d", invoice.Amount, tt.amount)
+			}
+			if invoice.BaseAmount != tt.amount {
+				t.Errorf("BaseAmount = %d, want %d", invoice.BaseAmount, tt.amount)
+			}
+
+			for idx, item := range invoice.Items {
+				if tt.taxable != nil && item.TaxableAmount != tt.taxable[idx] {
+					t.Errorf("item %d TaxableAmount = %d, want %d", idx, item.TaxableAmount, tt.taxable[idx])
+				}
+				if tt.taxAmount != nil && item.TaxAmount != tt.taxAmount[idx] {
+					t.Errorf("item %d TaxAmount = %d, want %d", idx, item.TaxAmount, tt.taxAmount[idx])
+				}
+				if tt.shares != nil && item.InvoiceDiscount != tt.shares[idx] {
+					t.Errorf("item %d InvoiceDiscount = %d, want %d", idx, item.InvoiceDiscount, tt.shares[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateTaxesOrder(t *testing.T) {
+	// Pajak compound dihitung dari dasar ditambah pajak sebelumnya sesuai urutan
+	item := InvoiceItem{TotalPrice: 1000_00, Taxes: []ItemTax{
+		{Name: "A", Rate: 10},
+		{Name: "B", Rate: 5, Compound: true},
+		{Name: "C", Rate: 2},
+	}}
+	item.calculateTaxes()
+
+	want := []Money{100_00, 55_00, 20_00}
+	for idx, tax := range item.Taxes {
+		if tax.Amount != want[idx] {
+			t.Errorf("pajak %s = %d, want %d", tax.Name, tax.Amount, want[idx])
+		}
+	}
+	if item.TaxAmount != 175_00 {
+		t.Errorf("TaxAmount = %d, want %d", item.TaxAmount, Money(175_00))
+	}
+}
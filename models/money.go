@@ -0,0 +1,154 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Money adalah nominal uang dalam satuan 1/100 (sen), disimpan sebagai bigint
+// supaya penjumlahan tidak pernah meleset seperti float64.
+//
+// Aturan pembulatan: setiap hasil perkalian (persen, pajak, kurs, pembagian
+// proporsional) dibulatkan ke sen terdekat, nilai tepat di tengah dibulatkan
+// menjauhi nol. Input JSON dengan lebih dari 2 desimal dibulatkan dengan cara
+// yang sama.
+type Money int64
+
+const moneyScale = 100
+
+var errInvalidMoney = errors.New("format nominal tidak valid")
+
+// moneyPattern hanya menerima desimal biasa, tanpa pecahan "1/3" atau eksponen "1e25"
+var moneyPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// ParseMoney membaca nominal desimal seperti "1500000", "12.5" atau "-3.25".
+// Nilai yang melebihi batas int64 dalam sen ditolak.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if !moneyPattern.MatchString(s) {
+		return 0, errInvalidMoney
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, errInvalidMoney
+	}
+	q := roundRatInt(r.Mul(r, big.NewRat(moneyScale, 1)))
+	if !q.IsInt64() {
+		return 0, errInvalidMoney
+	}
+	return Money(q.Int64()), nil
+}
+
+// MoneyFromFloat dipakai untuk nilai float lama (misalnya isi JSON tersimpan)
+func MoneyFromFloat(f float64) Money {
+	m, _ := ParseMoney(strconv.FormatFloat(f, 'f', -1, 64))
+	return m
+}
+
+// roundRat membulatkan rasional ke bilangan bulat, setengah menjauhi nol
+func roundRat(r *big.Rat) Money {
+	return Money(roundRatInt(r).Int64())
+}
+
+// roundRatInt sama dengan roundRat tetapi tanpa memotong ke int64
+func roundRatInt(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+// decimalRat mengubah float menjadi pecahan tepat berdasarkan representasi
+// desimal terpendeknya, jadi 0.1 benar-benar 1/10
+func decimalRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return r
+}
+
+// rateRat mengubah persen float (misalnya 11 atau 2.5) menjadi pecahan tepat
+func rateRat(percent float64) *big.Rat {
+	r := decimalRat(percent)
+	return r.Quo(r, big.NewRat(100, 1))
+}
+
+func (m Money) rat() *big.Rat {
+	return big.NewRat(int64(m), moneyScale)
+}
+
+// Mul mengalikan nominal dengan jumlah barang
+func (m Money) Mul(qty int) Money {
+	return m * Money(qty)
+}
+
+// MulRat mengalikan nominal dengan pecahan lalu membulatkan ke sen
+func (m Money) MulRat(r *big.Rat) Money {
+	return roundRat(new(big.Rat).Mul(big.NewRat(int64(m), 1), r))
+}
+
+// Percent menghitung persentase dari nominal, misalnya Percent(11) untuk PPN 11%
+func (m Money) Percent(percent float64) Money {
+	return m.MulRat(rateRat(percent))
+}
+
+// Convert mengonversi nominal dengan kurs, misalnya USD ke IDR
+func (m Money) Convert(rate float64) Money {
+	return m.MulRat(decimalRat(rate))
+}
+
+// Ratio menghitung m * num / den, dipakai untuk membagi nominal secara proporsional
+func (m Money) Ratio(num, den Money) Money {
+	if den == 0 {
+		return 0
+	}
+	return m.MulRat(big.NewRat(int64(num), int64(den)))
+}
+
+// Units mengembalikan bagian rupiah (bilangan bulat) dan sen
+func (m Money) Units() (int64, int64) {
+	return int64(m) / moneyScale, int64(m) % moneyScale
+}
+
+// String menulis nominal dalam bentuk desimal tanpa nol di belakang, misalnya "1500000.5"
+func (m Money) String() string {
+	return trimDecimal(m.rat().FloatString(2))
+}
+
+func trimDecimal(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON menerima angka JSON maupun string, tanpa melewati float64
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*m = 0
+		return nil
+	}
+
+	v, err := ParseMoney(s)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, s)
+	}
+	*m = v
+	return nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "1500000", want: 1500000_00},
+		{input: "12.5", want: 1250},
+		{input: "-3.25", want: -325},
+		{input: " 7 ", want: 700},
+		{input: "0.005", want: 1},
+		{input: "-0.005", want: -1},
+		{input: "0.004", want: 0},
+		{input: "92233720368547758.07", want: 9223372036854775807},
+		{input: "", wantErr: true},
+		{input: "1/3", wantErr: true},
+		{input: "1e25", wantErr: true},
+		{input: "1E2", wantErr: true},
+		{input: "+5", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "5.", wantErr: true},
+		{input: "1,5", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "92233720368547758.08", wantErr: true},
+		{input: "100000000000000000000000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %d, want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMoney(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	var m Money
+	if err := m.UnmarshalJSON([]byte(`"1/3"`)); err == nil {
+		t.Errorf("UnmarshalJSON(\"1/3\") = %d, want error", m)
+	}
+	if err := m.UnmarshalJSON([]byte(`1e25`)); err == nil {
+		t.Errorf("UnmarshalJSON(1e25) = %d, want error", m)
+	}
+	if err := m.UnmarshalJSON([]byte(`12.34`)); err != nil || m != 1234 {
+		t.Errorf("UnmarshalJSON(12.34) = %d, %v, want 1234", m, err)
+	}
+}
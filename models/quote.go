@@ -0,0 +1,178 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status quote (penawaran harga)
+const (
+	QuoteStatusDraft    = "Draft"
+	QuoteStatusSent     = "Terkirim"
+	QuoteStatusAccepted = "Diterima"
+	QuoteStatusDeclined = "Ditolak"
+	QuoteStatusExpired  = "Kedaluwarsa"
+)
+
+// DefaultQuoteValidDays adalah masa berlaku quote jika expiry_date tidak diisi
+const DefaultQuoteValidDays = 30
+
+var QuoteStatuses = []string{
+	QuoteStatusDraft, QuoteStatusSent, QuoteStatusAccepted, QuoteStatusDeclined, QuoteStatusExpired,
+}
+
+// quoteTransitions berisi perpindahan status quote yang diizinkan. Quote yang
+// kedaluwarsa boleh dikirim ulang setelah tanggal berlakunya diperpanjang.
+var quoteTransitions = map[string][]string{
+	QuoteStatusDraft:    {QuoteStatusSent, QuoteStatusAccepted},
+	QuoteStatusSent:     {QuoteStatusAccepted, QuoteStatusDeclined, QuoteStatusExpired},
+	QuoteStatusAccepted: {},
+	QuoteStatusDeclined: {},
+	QuoteStatusExpired:  {QuoteStatusSent},
+}
+
+// AllowedQuoteTransitions mengembalikan status tujuan yang sah dari status quote saat ini
+func AllowedQuoteTransitions(from string) []string {
+	return quoteTransitions[from]
+}
+
+func CanTransitionQuote(from, to string) bool {
+	for _, s := range quoteTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsQuoteEditable menandakan isi quote masih boleh diubah
+func IsQuoteEditable(status string) bool {
+	return status == QuoteStatusDraft || status == QuoteStatusSent || status == QuoteStatusExpired
+}
+
+type Quote struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	UserID         uint           `json:"user_id" gorm:"uniqueIndex:idx_quotes_user_number,where:number <> ''"`
+	ClientID       uint           `json:"client_id" gorm:"index"`
+	Number         string         `json:"quote_number" gorm:"uniqueIndex:idx_quotes_user_number"`
+	IssueDate      string         `json:"issue_date"`
+	ExpiryDate     string         `json:"expiry_date"` // berlaku sampai tanggal ini
+	Status         string         `json:"status"`
+	Note           string         `json:"note"`
+	DiscountType   string         `json:"discount_type"`
+	DiscountValue  Money          `json:"discount_value"`
+	DiscountAmount Money          `json:"discount_amount"`
+	Subtotal       Money          `json:"subtotal"`
+	TaxTotal       Money          `json:"tax_total"`
+	Amount         Money          `json:"amount"`
+	Currency       string         `json:"currency" gorm:"size:3"`
+	ExchangeRate   float64        `json:"exchange_rate"`
+	BaseCurrency   string         `json:"base_currency" gorm:"size:3"`
+	BaseAmount     Money          `json:"base_amount"`
+	InvoiceID      *uint          `json:"invoice_id"` // invoice hasil konversi
+	AcceptedAt     *time.Time     `json:"accepted_at"`
+	DeclinedAt     *time.Time     `json:"declined_at"`
+	Items          []QuoteItem    `json:"items" gorm:"foreignKey:QuoteID"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// QuoteItem memiliki struktur yang sama dengan InvoiceItem supaya bisa disalin
+// apa adanya saat quote dikonversi
+type QuoteItem struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	QuoteID         uint      `json:"quote_id" gorm:"index"`
+	CatalogItemID   *uint     `json:"catalog_item_id,omitempty" gorm:"index"`
+	ItemName        string    `json:"item_name"`
+	Quantity        int       `json:"quantity"`
+	UnitPrice       Money     `json:"unit_price"`
+	TotalPrice      Money     `json:"total_price"`
+	DiscountType    string    `json:"discount_type"`
+	DiscountValue   Money     `json:"discount_value"`
+	DiscountAmount  Money     `json:"discount_amount"`
+	InvoiceDiscount Money     `json:"invoice_discount"`
+	TaxableAmount   Money     `json:"taxable_amount"`
+	TaxAmount       Money     `json:"tax_amount"`
+	Taxes           []ItemTax `json:"taxes" gorm:"serializer:json;type:jsonb"`
+	TaxRateIDs      []uint    `json:"tax_rate_ids,omitempty" gorm:"-"` // hanya untuk request
+	// UnitPriceOmitted sama seperti di InvoiceItem
+	UnitPriceOmitted bool      `json:"-" gorm:"-"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (item *QuoteItem) UnmarshalJSON(data []byte) error {
+	type plain QuoteItem
+	if err := json.Unmarshal(data, (*plain)(item)); err != nil {
+		return err
+	}
+	sent, err := jsonFieldSent(data, "unit_price")
+	item.UnitPriceOmitted = !sent
+	return err
+}
+
+// ToInvoice menyalin client, diskon dan item quote menjadi invoice baru
+// (belum disimpan). Dipakai untuk menghitung total dan saat konversi.
+func (q *Quote) ToInvoice() Invoice {
+	invoice := Invoice{
+		UserID:        q.UserID,
+		ClientID:      q.ClientID,
+		IssueDate:     q.IssueDate,
+		Note:          q.Note,
+		DiscountType:  q.DiscountType,
+		DiscountValue: q.DiscountValue,
+		Currency:      q.Currency,
+		ExchangeRate:  q.ExchangeRate,
+		BaseCurrency:  q.BaseCurrency,
+		Items:         make([]InvoiceItem, len(q.Items)),
+	}
+	for i, item := range q.Items {
+		invoice.Items[i] = InvoiceItem{
+			CatalogItemID: item.CatalogItemID,
+			ItemName:      item.ItemName,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			DiscountType:  item.DiscountType,
+			DiscountValue: item.DiscountValue,
+			Taxes:         append([]ItemTax(nil), item.Taxes...),
+			TaxRateIDs:    item.TaxRateIDs,
+			// harga yang tidak dikirim diisi dari katalog saat menghitung quote
+			UnitPriceOmitted: item.UnitPriceOmitted,
+		}
+	}
+	return invoice
+}
+
+// CopyTotals menyalin hasil perhitungan invoice kembali ke quote
+func (q *Quote) CopyTotals(invoice Invoice) {
+	q.Currency = invoice.Currency
+	q.ExchangeRate = invoice.ExchangeRate
+	q.BaseCurrency = invoice.BaseCurrency
+	q.Subtotal = invoice.Subtotal
+	q.DiscountAmount = invoice.DiscountAmount
+	q.TaxTotal = invoice.TaxTotal
+	q.Amount = invoice.Amount
+	q.BaseAmount = invoice.BaseAmount
+
+	for i := range q.Items {
+		calc := invoice.Items[i]
+		item := &q.Items[i]
+		item.ItemName = calc.ItemName // bisa terisi dari katalog
+		item.UnitPrice = calc.UnitPrice
+		item.TotalPrice = calc.TotalPrice
+		item.DiscountAmount = calc.DiscountAmount
+		item.InvoiceDiscount = calc.InvoiceDiscount
+		item.TaxableAmount = calc.TaxableAmount
+		item.TaxAmount = calc.TaxAmount
+		item.Taxes = calc.Taxes
+	}
+}
+
+// TaxSummaries mengelompokkan pajak semua item quote per tarif
+func (q *Quote) TaxSummaries() []TaxSummary {
+	calc := q.ToInvoice()
+	return calc.TaxSummaries()
+}
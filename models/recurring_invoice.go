@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Satuan interval invoice berulang
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+	IntervalYear  = "year"
+	IntervalCron  = "cron" // jadwal memakai ekspresi cron 5 kolom
+)
+
+type RecurringInvoice struct {
+	ID            uint                   `json:"id" gorm:"primaryKey"`
+	UserID        uint                   `json:"user_id" gorm:"index"`
+	ClientID      uint                   `json:"client_id"`
+	Name          string                 `json:"name"`
+	Currency      string                 `json:"currency" gorm:"size:3"`
+	Note          string                 `json:"note"`
+	DiscountType  string                 `json:"discount_type"`
+	DiscountValue Money                  `json:"discount_value"`
+	DueDays       int                    `json:"due_days"`       // jatuh tempo = tanggal terbit + DueDays
+	IntervalUnit  string                 `json:"interval_unit"`  // day, week, month, year atau cron
+	IntervalCount int                    `json:"interval_count"` // setiap N satuan
+	CronExpr      string                 `json:"cron_expr"`      // misalnya "0 0 1 * *" untuk setiap tanggal 1
+	StartDate     string                 `json:"start_date"`
+	EndDate       string                 `json:"end_date"`                   // kosong = tanpa akhir
+	NextRunDate   string                 `json:"next_run_date" gorm:"index"` // tanggal terbit invoice berikutnya
+	LastRunDate   string                 `json:"last_run_date"`              // tanggal terbit invoice terakhir
+	LastError     string                 `json:"last_error"`                 // error pembuatan terakhir, kosong jika berhasil
+	AutoSend      bool                   `json:"auto_send"`                  // langsung ubah ke Terkirim
+	Active        bool                   `json:"active" gorm:"index"`
+	Items         []RecurringInvoiceItem `json:"items" gorm:"foreignKey:RecurringInvoiceID"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt         `json:"-" gorm:"index"`
+}
+
+type RecurringInvoiceItem struct {
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	RecurringInvoiceID uint      `json:"recurring_invoice_id" gorm:"index"`
+	ItemName           string    `json:"item_name"`
+	Quantity           int       `json:"quantity"`
+	UnitPrice          Money     `json:"unit_price"`
+	DiscountType       string    `json:"discount_type"`
+	DiscountValue      Money     `json:"discount_value"`
+	TaxRateIDs         []uint    `json:"tax_rate_ids" gorm:"serializer:json"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// InvoiceItems mengubah item template menjadi item invoice baru
+func (r *RecurringInvoice) InvoiceItems() []InvoiceItem {
+	items := make([]InvoiceItem, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, InvoiceItem{
+			ItemName:      item.ItemName,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			DiscountType:  item.DiscountType,
+			DiscountValue: item.DiscountValue,
+			TaxRateIDs:    item.TaxRateIDs,
+		})
+	}
+	return items
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nada pengingat. Template bawaan makin tegas dari friendly sampai final.
+const (
+	ReminderToneFriendly = "friendly" // sebelum jatuh tempo
+	ReminderToneFirm     = "firm"     // hari jatuh tempo sampai 13 hari sesudahnya
+	ReminderToneUrgent   = "urgent"   // 14 sampai 29 hari lewat jatuh tempo
+	ReminderToneFinal    = "final"    // 30 hari atau lebih lewat jatuh tempo
+)
+
+// Template pengingat bawaan per nada, dipakai jika Subject/Body aturan dikosongkan
+var defaultReminderTemplates = map[string]struct{ Subject, Body string }{
+	ReminderToneFriendly: {
+		Subject: "Pengingat: invoice {{.Invoice.Number}} jatuh tempo {{.DueDate}}",
+		Body: `Yth. {{.ClientName}},
+
+Kami mengingatkan bahwa invoice {{.Invoice.Number}} dengan sisa tagihan {{.Balance}} akan jatuh tempo pada {{.DueDate}}.
+Abaikan email ini jika pembayaran sudah dilakukan.
+
+Terima kasih,
+{{.SenderName}}`,
+	},
+	ReminderToneFirm: {
+		Subject: "Invoice {{.Invoice.Number}} telah jatuh tempo",
+		Body: `Yth. {{.ClientName}},
+
+Invoice {{.Invoice.Number}} dengan sisa tagihan {{.Balance}} telah jatuh tempo pada {{.DueDate}}{{if .DaysOverdue}} ({{.DaysOverdue}} hari yang lalu){{end}}.
+Mohon segera melakukan pembayaran. Invoice terlampir.
+
+Terima kasih,
+{{.SenderName}}`,
+	},
+	ReminderToneUrgent: {
+		Subject: "Tagihan tertunggak: invoice {{.Invoice.Number}} belum dibayar",
+		Body: `Yth. {{.ClientName}},
+
+Sampai hari ini kami belum menerima pembayaran untuk invoice {{.Invoice.Number}} sebesar {{.Balance}} yang telah lewat jatuh tempo {{.DaysOverdue}} hari (jatuh tempo {{.DueDate}}).
+Mohon lakukan pembayaran dalam waktu 7 hari, atau hubungi kami jika ada kendala. Invoice terlampir.
+
+Hormat kami,
+{{.SenderName}}`,
+	},
+	ReminderToneFinal: {
+		Subject: "Pemberitahuan terakhir: invoice {{.Invoice.Number}} lewat {{.DaysOverdue}} hari",
+		Body: `Yth. {{.ClientName}},
+
+Invoice {{.Invoice.Number}} sebesar {{.Balance}} telah lewat jatuh tempo {{.DaysOverdue}} hari (jatuh tempo {{.DueDate}}) dan belum dibayar meskipun sudah beberapa kali kami ingatkan.
+Ini adalah pemberitahuan terakhir. Mohon segera lunasi tagihan ini. Jika tidak ada tanggapan, kami terpaksa menghentikan layanan dan menempuh langkah penagihan lebih lanjut.
+
+Hormat kami,
+{{.SenderName}}`,
+	},
+}
+
+// ReminderRule adalah satu jadwal pengingat relatif terhadap jatuh tempo invoice
+type ReminderRule struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	UserID     uint           `json:"user_id" gorm:"index"`
+	OffsetDays int            `json:"offset_days"` // -3 = 3 hari sebelum, 0 = hari jatuh tempo, 7 = 7 hari sesudah
+	Tone       string         `json:"tone"`        // nada template bawaan, kosong = mengikuti OffsetDays
+	Subject    string         `json:"subject"`     // template text/template, kosong = bawaan sesuai nada
+	Body       string         `json:"body" gorm:"type:text"`
+	Active     bool           `json:"active"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// EffectiveTone mengembalikan nada aturan. Tanpa nada pilihan, nadanya naik
+// seiring jauhnya jadwal dari jatuh tempo.
+func (r ReminderRule) EffectiveTone() string {
+	if _, ok := defaultReminderTemplates[r.Tone]; ok {
+		return r.Tone
+	}
+	switch {
+	case r.OffsetDays < 0:
+		return ReminderToneFriendly
+	case r.OffsetDays < 14:
+		return ReminderToneFirm
+	case r.OffsetDays < 30:
+		return ReminderToneUrgent
+	default:
+		return ReminderToneFinal
+	}
+}
+
+// Templates mengembalikan template subject dan isi email aturan ini
+func (r ReminderRule) Templates() (string, string) {
+	defaults := defaultReminderTemplates[r.EffectiveTone()]
+	subject, body := defaults.Subject, defaults.Body
+
+	if r.Subject != "" {
+		subject = r.Subject
+	}
+	if r.Body != "" {
+		body = r.Body
+	}
+	return subject, body
+}
+
+// DueReminderRule memilih aturan paling akhir yang sudah jatuh jadwal untuk
+// invoice yang lewat daysPastDue hari dari jatuh tempo (negatif = belum jatuh tempo).
+// Aturan yang terlewat sebelumnya tidak dikirim susul.
+func DueReminderRule(rules []ReminderRule, daysPastDue int) (ReminderRule, bool) {
+	var due ReminderRule
+	found := false
+	for _, rule := range rules {
+		if !rule.Active || rule.OffsetDays > daysPastDue {
+			continue
+		}
+		if !found || rule.OffsetDays > due.OffsetDays {
+			due = rule
+			found = true
+		}
+	}
+	return due, found
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TaxRate struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"index"`
+	Name      string         `json:"name"`      // "PPN", "PPh 23", dll.
+	Rate      float64        `json:"rate"`      // dalam persen, 11 untuk 11%
+	Inclusive bool           `json:"inclusive"` // harga item sudah termasuk pajak
+	Compound  bool           `json:"compound"`  // dihitung dari harga + pajak sebelumnya
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+)
+
+// Periode reset nomor urut dokumen
+const (
+	ResetYearly  = "yearly"
+	ResetMonthly = "monthly"
+)
+
+const (
+	DefaultInvoiceNumberPattern    = "INV/{YYYY}/{MM}/{seq:0000}"
+	DefaultCreditNoteNumberPattern = "CN/{YYYY}/{MM}/{seq:0000}"
+	DefaultQuoteNumberPattern      = "QUO/{YYYY}/{MM}/{seq:0000}"
+	DefaultCurrency                = "IDR"
+	DefaultTimezone                = "Asia/Jakarta"
+)
+
+// Template email invoice bawaan, memakai sintaks text/template
+const (
+	DefaultInvoiceEmailSubject = "Invoice {{.Invoice.Number}} dari {{.SenderName}}"
+	DefaultInvoiceEmailBody    = `Yth. {{.ClientName}},
+
+Terlampir invoice {{.Invoice.Number}} sebesar {{.Amount}} dengan jatuh tempo {{.DueDate}}.
+
+Terima kasih,
+{{.SenderName}}`
+)
+
+type UserSetting struct {
+	ID                      uint      `json:"id" gorm:"primaryKey"`
+	UserID                  uint      `json:"user_id" gorm:"uniqueIndex"`
+	InvoiceNumberPattern    string    `json:"invoice_number_pattern"`
+	InvoiceNumberReset      string    `json:"invoice_number_reset"` // "yearly" atau "monthly", juga untuk credit note dan quote
+	CreditNoteNumberPattern string    `json:"credit_note_number_pattern"`
+	QuoteNumberPattern      string    `json:"quote_number_pattern"`
+	BaseCurrency            string    `json:"base_currency" gorm:"size:3"` // mata uang laporan
+	Timezone                string    `json:"timezone"`                    // zona waktu IANA, misalnya "Asia/Jakarta"
+	InvoiceEmailSubject     string    `json:"invoice_email_subject"`
+	InvoiceEmailBody        string    `json:"invoice_email_body" gorm:"type:text"`
+	InvoiceTemplate         string    `json:"invoice_template"` // key template PDF default
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// ApplyDefaults mengisi nilai kosong dengan pengaturan bawaan
+func (s *UserSetting) ApplyDefaults() {
+	if s.InvoiceNumberPattern == "" {
+		s.InvoiceNumberPattern = DefaultInvoiceNumberPattern
+	}
+	if s.CreditNoteNumberPattern == "" {
+		s.CreditNoteNumberPattern = DefaultCreditNoteNumberPattern
+	}
+	if s.QuoteNumberPattern == "" {
+		s.QuoteNumberPattern = DefaultQuoteNumberPattern
+	}
+	if s.InvoiceNumberReset == "" {
+		s.InvoiceNumberReset = ResetYearly
+	}
+	if s.BaseCurrency == "" {
+		s.BaseCurrency = DefaultCurrency
+	}
+	if s.Timezone == "" {
+		s.Timezone = DefaultTimezone
+	}
+	if s.InvoiceEmailSubject == "" {
+		s.InvoiceEmailSubject = DefaultInvoiceEmailSubject
+	}
+	if s.InvoiceEmailBody == "" {
+		s.InvoiceEmailBody = DefaultInvoiceEmailBody
+	}
+	if s.InvoiceTemplate == "" {
+		s.InvoiceTemplate = DefaultInvoiceTemplate
+	}
+}
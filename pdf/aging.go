@@ -0,0 +1,71 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+)
+
+// RenderAgingReport menulis laporan umur piutang per client ke w dalam A4 landscape
+func RenderAgingReport(w io.Writer, report models.AgingReport, issuer models.BusinessProfile) error {
+	pdf := newDocument("L")
+	pdf.AddPage()
+
+	// Penerbit
+	writeIssuer(pdf, issuer, models.LogoRight)
+
+	// Judul
+	pdf.SetFont(fontFamily, "B", 20)
+	pdf.Cell(0, 10, "LAPORAN UMUR PIUTANG")
+	pdf.Ln(12)
+
+	pdf.SetFont(fontFamily, "", 12)
+	asOf, _ := time.Parse("2006-01-02", report.AsOf)
+	pdf.Cell(0, 6, fmt.Sprintf("%s - per %s (%s)", issuer.LegalName, asOf.Format("02 January 2006"), report.BaseCurrency))
+	pdf.Ln(10)
+
+	headers := []string{"CLIENT", "BELUM JT", "1-30", "31-60", "61-90", "> 90", "TOTAL"}
+	widths := []float64{67, 35, 35, 35, 35, 35, 35}
+
+	pdf.SetFont(fontFamily, "B", 10)
+	for i, header := range headers {
+		align := "C"
+		if i == 0 {
+			align = ""
+		}
+		pdf.CellFormat(widths[i], 10, header, "B", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	row := func(name string, b models.AgingBuckets, fill bool) {
+		values := []models.Money{b.Current, b.Days1To30, b.Days31To60, b.Days61To90, b.Over90, b.Total}
+		pdf.CellFormat(widths[0], 8, name, "", 0, "", fill, 0, "")
+		for i, value := range values {
+			pdf.CellFormat(widths[i+1], 8, FormatMoney(value, report.BaseCurrency), "", 0, "R", fill, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for i, client := range report.Clients {
+		row(client.Name, client.Buckets, i%2 == 0)
+	}
+	if len(report.Clients) == 0 {
+		pdf.CellFormat(0, 8, "Tidak ada piutang pada tanggal ini", "", 1, "", false, 0, "")
+	}
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(277, 1, "", "T", 1, "", false, 0, "")
+	row("TOTAL", report.Totals, false)
+
+	if report.ExcludedCount > 0 {
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "I", 9)
+		pdf.Cell(0, 6, fmt.Sprintf("%d invoice dengan mata uang dasar lain tidak diikutkan", report.ExcludedCount))
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sholllll662/invoice-backend/models"
+)
+
+// RenderCreditNote menulis PDF credit note ke w dengan tata letak yang sama
+// seperti invoice, ditambah rujukan ke invoice asli dan pemakaian kreditnya
+func RenderCreditNote(w io.Writer, note models.CreditNote, invoiceNumber string, issuer models.BusinessProfile, client models.Client) error {
+	pdf := newDocument("P")
+	pdf.AddPage()
+
+	// Penerbit
+	writeIssuer(pdf, issuer, models.LogoRight)
+
+	// Judul
+	pdf.SetFont(fontFamily, "B", 20)
+	pdf.Cell(0, 10, "CREDIT NOTE")
+	pdf.Ln(12)
+
+	// Informasi KEPADA dan TANGGAL
+	issueDate, _ := time.Parse("2006-01-02", note.IssueDate)
+	writeRecipient(pdf, client, "TANGGAL :", issueDate.Format("Monday, 02 January 2006"))
+
+	// Nomor credit note dan invoice asli
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.Cell(50, 6, "NO CREDIT NOTE :")
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.Cell(60, 6, note.Number)
+	pdf.Ln(6)
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.Cell(50, 6, "UNTUK INVOICE :")
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.Cell(60, 6, invoiceNumber)
+	pdf.Ln(6)
+	if note.Reason != "" {
+		pdf.SetFont(fontFamily, "B", 12)
+		pdf.Cell(50, 6, "ALASAN :")
+		pdf.SetFont(fontFamily, "", 12)
+		pdf.MultiCell(140, 6, note.Reason, "", "", false)
+	}
+	pdf.Ln(4)
+
+	// Header tabel item
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(80, 10, "KETERANGAN", "0", 0, "", false, 0, "")
+	pdf.CellFormat(40, 10, "HARGA", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(30, 10, "JML", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 10, "TOTAL", "0", 1, "C", false, 0, "")
+
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.SetFillColor(230, 230, 230)
+	for i, item := range note.Items {
+		border := "B"
+		if i == len(note.Items)-1 {
+			border = ""
+		}
+		pdf.CellFormat(80, 10, item.ItemName, border, 0, "", true, 0, "")
+		pdf.CellFormat(40, 10, FormatMoney(item.UnitPrice, note.Currency), border, 0, "C", true, 0, "")
+		pdf.CellFormat(30, 10, strconv.Itoa(item.Quantity), border, 0, "C", true, 0, "")
+		pdf.CellFormat(40, 10, FormatMoney(item.TotalPrice, note.Currency), border, 1, "C", true, 0, "")
+	}
+
+	// Subtotal
+	pdf.Ln(4)
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(120, 10, "", "0", 0, "", false, 0, "")
+	pdf.CellFormat(30, 10, "Sub Total", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 10, FormatMoney(note.Subtotal, note.Currency), "0", 1, "C", false, 0, "")
+
+	// Ringkasan pajak per tarif
+	pdf.SetFont(fontFamily, "", 12)
+	for _, tax := range note.TaxSummaries() {
+		label := fmt.Sprintf("%s %s%%", tax.Name, humanize.Ftoa(tax.Rate))
+		if tax.Inclusive {
+			label += " (termasuk)"
+		}
+		pdf.CellFormat(110, 8, "", "0", 0, "", false, 0, "")
+		pdf.CellFormat(40, 8, label, "0", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 8, FormatMoney(tax.Amount, note.Currency), "0", 1, "C", false, 0, "")
+	}
+
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(120, 10, "", "0", 0, "", false, 0, "")
+	pdf.CellFormat(30, 10, "Total Kredit", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 10, FormatMoney(note.Amount, note.Currency), "0", 1, "C", false, 0, "")
+
+	// Pemakaian kredit
+	pdf.SetFont(fontFamily, "", 12)
+	rows := []struct {
+		label  string
+		amount models.Money
+	}{
+		{"Dipakai ke invoice", note.AmountApplied},
+		{"Dikembalikan", note.AmountRefunded},
+		{"Sisa kredit", note.Balance},
+	}
+	for _, row := range rows {
+		pdf.CellFormat(110, 8, "", "0", 0, "", false, 0, "")
+		pdf.CellFormat(40, 8, row.label, "0", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 8, FormatMoney(row.amount, note.Currency), "0", 1, "C", false, 0, "")
+	}
+
+	if note.Status == models.CreditNoteStatusVoid {
+		pdf.Ln(10)
+		pdf.SetFont(fontFamily, "B", 16)
+		pdf.SetTextColor(200, 0, 0)
+		pdf.Cell(0, 10, "VOID")
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	pdf.Ln(20)
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.Cell(0, 10, issuer.LegalName)
+	pdf.Ln(10)
+	writeFooterText(pdf, issuer)
+
+	return pdf.Output(w)
+}
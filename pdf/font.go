@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	_ "embed"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Font DejaVu Sans Condensed ikut di-embed supaya nama client dan item dengan
+// huruf di luar Latin-1 tercetak benar tanpa bergantung pada font di server
+var (
+	//go:embed fonts/DejaVuSansCondensed.ttf
+	fontRegular []byte
+	//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+	fontBold []byte
+	//go:embed fonts/DejaVuSansCondensed-Oblique.ttf
+	fontItalic []byte
+)
+
+const fontFamily = "DejaVu"
+
+// maxFontRune adalah rune terbesar yang punya lebar di tabel font gofpdf.
+// Rune di atasnya (emoji dan bidang astral lain) membuat gofpdf panic.
+const maxFontRune = 0xFFFF
+
+// sanitizeText mengganti rune yang tidak bisa dicetak font dengan U+FFFD
+func sanitizeText(s string) string {
+	clean := true
+	for _, r := range s {
+		if r > maxFontRune || r == utf8.RuneError {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if r > maxFontRune {
+			r = utf8.RuneError
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// document membungkus gofpdf supaya setiap teks yang dicetak atau diukur
+// dibersihkan dulu dengan sanitizeText
+type document struct {
+	*gofpdf.Fpdf
+}
+
+func (d *document) Cell(w, h float64, txt string) {
+	d.Fpdf.Cell(w, h, sanitizeText(txt))
+}
+
+func (d *document) CellFormat(w, h float64, txt, borderStr string, ln int, alignStr string, fill bool, link int, linkStr string) {
+	d.Fpdf.CellFormat(w, h, sanitizeText(txt), borderStr, ln, alignStr, fill, link, linkStr)
+}
+
+func (d *document) MultiCell(w, h float64, txt, borderStr, alignStr string, fill bool) {
+	d.Fpdf.MultiCell(w, h, sanitizeText(txt), borderStr, alignStr, fill)
+}
+
+func (d *document) SplitText(txt string, w float64) []string {
+	return d.Fpdf.SplitText(sanitizeText(txt), w)
+}
+
+func (d *document) GetStringWidth(s string) float64 {
+	return d.Fpdf.GetStringWidth(sanitizeText(s))
+}
+
+// newDocument membuat dokumen A4 dengan font Unicode terdaftar. Alias {nb}
+// diganti jumlah halaman saat dokumen ditutup.
+func newDocument(orientation string) *document {
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	pdf.AliasNbPages("")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", fontRegular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", fontBold)
+	pdf.AddUTF8FontFromBytes(fontFamily, "I", fontItalic)
+	return &document{Fpdf: pdf}
+}
@@ -0,0 +1,72 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sholllll662/invoice-backend/models"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := map[string]string{
+		"Jasa desain":    "Jasa desain",
+		"Łukasz – Çağrı": "Łukasz – Çağrı",
+		"Jasa 🚀":         "Jasa �",
+		"a\xffb":         "a�b",
+		"🚀🚀":             "��",
+		"":               "",
+	}
+	for input, want := range tests {
+		if got := sanitizeText(input); got != want {
+			t.Errorf("sanitizeText(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// Emoji di teks mana pun tidak boleh membuat gofpdf panic
+func TestRenderWithAstralRunes(t *testing.T) {
+	issuer := models.BusinessProfile{LegalName: "Toko 🛒", Address: "Jl. 🌴 No. 1", FooterText: "Terima kasih 🙏"}
+	client := models.Client{Nama: "Budi 😀", Email: "budi@contoh.id", NoTlp: "0812 📞"}
+	invoice := models.Invoice{
+		Number:    "INV/2025/01/0001",
+		IssueDate: "2025-01-15",
+		DueDate:   "2025-01-29",
+		Status:    models.StatusSent,
+		Currency:  "IDR",
+		Note:      "Catatan 📝 panjang",
+		Items: []models.InvoiceItem{
+			{ItemName: "Jasa 🚀", Quantity: 1, UnitPrice: 100_000_00, Taxes: []models.ItemTax{{Name: "PPN 🧾", Rate: 11}}},
+		},
+	}
+	invoice.CalculateTotals()
+	invoice.UpdateBalance(0)
+
+	for _, template := range models.BuiltinInvoiceTemplates() {
+		template.TermsText = "Syarat 📜"
+		var buf bytes.Buffer
+		if err := RenderInvoice(&buf, invoice, issuer, client, template); err != nil {
+			t.Fatalf("RenderInvoice(%s): %v", template.Key, err)
+		}
+	}
+
+	quote := models.Quote{Number: "QUO 🚀", IssueDate: "2025-01-15", ExpiryDate: "2025-02-14", Currency: "IDR",
+		Items: []models.QuoteItem{{ItemName: "Jasa 🚀", Quantity: 1, UnitPrice: 100_00}}}
+	var buf bytes.Buffer
+	if err := RenderQuote(&buf, quote, issuer, client); err != nil {
+		t.Fatalf("RenderQuote: %v", err)
+	}
+
+	note := models.CreditNote{Number: "CN 🚀", IssueDate: "2025-01-15", Reason: "Retur 📦",
+		Items: []models.CreditNoteItem{{ItemName: "Jasa 🚀", Quantity: 1, UnitPrice: 100_00}}}
+	buf.Reset()
+	if err := RenderCreditNote(&buf, note, "INV 🚀", issuer, client); err != nil {
+		t.Fatalf("RenderCreditNote: %v", err)
+	}
+
+	report := models.AgingReport{AsOf: "2025-01-15", BaseCurrency: "IDR",
+		Clients: []models.AgingClient{{Name: "Budi 😀", Invoices: []models.AgingInvoice{{Number: "INV 🚀"}}}}}
+	buf.Reset()
+	if err := RenderAgingReport(&buf, report, issuer); err != nil {
+		t.Fatalf("RenderAgingReport: %v", err)
+	}
+}
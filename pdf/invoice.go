@@ -0,0 +1,292 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+)
+
+// FormatMoney menulis nominal dengan simbol mata uang dan pemisah ribuan.
+// Sen selalu ditampilkan untuk mata uang berdesimal, untuk IDR hanya jika ada.
+func FormatMoney(amount models.Money, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	units, cents := amount.Units()
+	number := humanize.Comma(units)
+	if utils.CurrencyDecimals(currency) > 0 || cents != 0 {
+		number += fmt.Sprintf(".%02d", cents)
+	}
+	return fmt.Sprintf("%s%s %s", sign, utils.CurrencySymbol(currency), number)
+}
+
+func discountLabel(discountType string, value models.Money) string {
+	if discountType == models.DiscountPercent {
+		return fmt.Sprintf("Diskon %s%%", value)
+	}
+	return "Diskon"
+}
+
+// invoiceStyle adalah ukuran huruf dan warna tabel untuk satu tata letak
+type invoiceStyle struct {
+	titleSize  float64
+	fontSize   float64
+	rowHeight  float64
+	moneyAlign string
+	headerFill bool // header tabel berlatar warna aksen
+	rowFill    bool // baris item berlatar abu-abu
+}
+
+var invoiceStyles = map[string]invoiceStyle{
+	models.LayoutClassic: {titleSize: 20, fontSize: 12, rowHeight: 10, moneyAlign: "C", rowFill: true},
+	models.LayoutModern:  {titleSize: 22, fontSize: 10, rowHeight: 9, moneyAlign: "R", headerFill: true},
+	models.LayoutCompact: {titleSize: 16, fontSize: 9, rowHeight: 6, moneyAlign: "R"},
+}
+
+// invoiceColumn adalah satu kolom tabel item
+type invoiceColumn struct {
+	key   string
+	title string
+	width float64
+	value func(item models.InvoiceItem) string
+}
+
+const invoiceTableWidth = 190 // lebar A4 dikurangi margin kiri dan kanan
+
+// invoiceColumns menyusun kolom tabel dari template. Lebar kolom keterangan
+// mengisi sisa lebar tabel.
+func invoiceColumns(template models.InvoiceTemplate, currency string) []invoiceColumn {
+	showDiscount := template.HasColumn(models.ColumnDiscount)
+
+	var columns []invoiceColumn
+	for _, key := range template.Columns {
+		switch key {
+		case models.ColumnDescription:
+			columns = append(columns, invoiceColumn{key: key, title: "KETERANGAN", value: func(item models.InvoiceItem) string {
+				return item.ItemName
+			}})
+		case models.ColumnUnitPrice:
+			columns = append(columns, invoiceColumn{key: key, title: "HARGA", width: 35, value: func(item models.InvoiceItem) string {
+				return FormatMoney(item.UnitPrice, currency)
+			}})
+		case models.ColumnQuantity:
+			columns = append(columns, invoiceColumn{key: key, title: "JML", width: 18, value: func(item models.InvoiceItem) string {
+				return strconv.Itoa(item.Quantity)
+			}})
+		case models.ColumnDiscount:
+			columns = append(columns, invoiceColumn{key: key, title: "DISKON", width: 30, value: func(item models.InvoiceItem) string {
+				if item.DiscountAmount == 0 {
+					return ""
+				}
+				return "-" + FormatMoney(item.DiscountAmount, currency)
+			}})
+		case models.ColumnTax:
+			columns = append(columns, invoiceColumn{key: key, title: "PAJAK", width: 30, value: func(item models.InvoiceItem) string {
+				labels := make([]string, len(item.Taxes))
+				for i, tax := range item.Taxes {
+					labels[i] = fmt.Sprintf("%s %s%%", tax.Name, humanize.Ftoa(tax.Rate))
+				}
+				return strings.Join(labels, ", ")
+			}})
+		case models.ColumnTotal:
+			// Tanpa kolom diskon, diskon baris dicetak di baris tersendiri di bawah item
+			columns = append(columns, invoiceColumn{key: key, title: "TOTAL", width: 37, value: func(item models.InvoiceItem) string {
+				if showDiscount {
+					return FormatMoney(item.TotalPrice-item.DiscountAmount, currency)
+				}
+				return FormatMoney(item.TotalPrice, currency)
+			}})
+		}
+	}
+
+	rest := float64(invoiceTableWidth)
+	for _, column := range columns {
+		rest -= column.width
+	}
+	for i := range columns {
+		if columns[i].key == models.ColumnDescription {
+			columns[i].width = rest
+		}
+	}
+	return columns
+}
+
+// invoiceStatusLabel mencetak status invoice, ditambah keterangan lewat jatuh tempo
+func invoiceStatusLabel(invoice models.Invoice) string {
+	if invoice.IsOverdue && invoice.Balance > 0 {
+		return invoice.Status + " (lewat jatuh tempo)"
+	}
+	return invoice.Status
+}
+
+// RenderInvoice menulis PDF invoice ke w dengan tampilan dari template.
+// Dipakai untuk unduhan, lampiran email maupun pratinjau template. Invoice
+// panjang berlanjut ke halaman berikutnya dengan header tabel diulang dan
+// nomor halaman di kaki setiap halaman.
+func RenderInvoice(w io.Writer, invoice models.Invoice, issuer models.BusinessProfile, client models.Client, template models.InvoiceTemplate) error {
+	style, ok := invoiceStyles[template.Layout]
+	if !ok {
+		style = invoiceStyles[models.LayoutClassic]
+	}
+	accentR, accentG, accentB := template.AccentRGB()
+
+	// Buat PDF
+	pdf := newDocument("P")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 20)
+
+	// Halaman lanjutan diberi penanda invoice di atasnya
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, fmt.Sprintf("INVOICE %s - %s (lanjutan)", invoice.Number, issuer.LegalName), "", 1, "R", false, 0, "")
+		pdf.Ln(3)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Halaman %d dari {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// Penerbit
+	writeIssuer(pdf, issuer, template.LogoPosition)
+
+	// Judul
+	pdf.SetFont(fontFamily, "B", style.titleSize)
+	pdf.SetTextColor(accentR, accentG, accentB)
+	pdf.Cell(0, 10, "INVOICE")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(12)
+	if template.Layout == models.LayoutModern {
+		pdf.SetDrawColor(accentR, accentG, accentB)
+		pdf.Line(10, pdf.GetY()-2, 10+invoiceTableWidth, pdf.GetY()-2)
+		pdf.SetDrawColor(0, 0, 0)
+	}
+
+	// Informasi KEPADA dan TANGGAL
+	issueDate, _ := time.Parse("2006-01-02", invoice.IssueDate)
+	writeRecipient(pdf, client, "TANGGAL :", issueDate.Format("Monday, 02 January 2006"))
+
+	// Nomor invoice, jatuh tempo dan status
+	dueDate := invoice.DueDate
+	if due, err := time.Parse("2006-01-02", invoice.DueDate); err == nil {
+		dueDate = due.Format("02 January 2006")
+	}
+	for _, field := range [][2]string{
+		{"NO INVOICE :", invoice.Number},
+		{"JATUH TEMPO :", dueDate},
+		{"STATUS :", invoiceStatusLabel(invoice)},
+	} {
+		pdf.SetFont(fontFamily, "B", style.fontSize)
+		pdf.Cell(40, 6, field[0])
+		pdf.SetFont(fontFamily, "", style.fontSize)
+		pdf.Cell(100, 6, field[1])
+		pdf.Ln(6)
+	}
+	pdf.Ln(4)
+
+	// Tabel item
+	table := newInvoiceTable(pdf, invoiceColumns(template, invoice.Currency), style, [3]int{accentR, accentG, accentB})
+	table.items(invoice)
+
+	// Baris total sejajar dengan kolom total tabel
+	valueWidth := table.totalWidth()
+	totalRow := func(label, value string, bold bool) {
+		fontStyle := ""
+		if bold {
+			fontStyle = "B"
+		}
+		pdf.SetFont(fontFamily, fontStyle, style.fontSize)
+		pdf.CellFormat(invoiceTableWidth-valueWidth-50, style.rowHeight, "", "0", 0, "", false, 0, "")
+		pdf.CellFormat(50, style.rowHeight, label, "0", 0, "R", false, 0, "")
+		pdf.CellFormat(valueWidth, style.rowHeight, value, "0", 1, style.moneyAlign, false, 0, "")
+	}
+
+	// Ringkasan total tidak dipisah dari baris terakhirnya jika masih muat satu halaman
+	taxes := invoice.TaxSummaries()
+	summaryRows := 1 + len(taxes)
+	if invoice.DiscountAmount > 0 {
+		summaryRows++
+	}
+	if len(taxes) > 0 || invoice.DiscountAmount > 0 {
+		summaryRows++
+	}
+	if !table.fits(4 + float64(summaryRows)*style.rowHeight) {
+		pdf.AddPage()
+	}
+
+	// Subtotal
+	pdf.Ln(4)
+	totalRow("Sub Total", FormatMoney(invoice.Subtotal, invoice.Currency), true)
+
+	// Diskon tingkat invoice
+	if invoice.DiscountAmount > 0 {
+		totalRow(discountLabel(invoice.DiscountType, invoice.DiscountValue), "-"+FormatMoney(invoice.DiscountAmount, invoice.Currency), false)
+	}
+
+	// Ringkasan pajak per tarif
+	for _, tax := range taxes {
+		label := fmt.Sprintf("%s %s%%", tax.Name, humanize.Ftoa(tax.Rate))
+		if tax.Inclusive {
+			label += " (termasuk)"
+		}
+		totalRow(label, FormatMoney(tax.Amount, invoice.Currency), false)
+	}
+
+	if len(taxes) > 0 || invoice.DiscountAmount > 0 {
+		totalRow("Total", FormatMoney(invoice.Amount, invoice.Currency), true)
+	}
+
+	// Sisa tagihan jika sudah ada pembayaran atau kredit
+	if invoice.Balance != invoice.Amount {
+		totalRow("Sisa Tagihan", FormatMoney(invoice.Balance, invoice.Currency), true)
+	}
+
+	// Catatan invoice
+	if invoice.Note != "" {
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "B", style.fontSize)
+		pdf.Cell(0, 6, "CATATAN :")
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "", style.fontSize)
+		pdf.MultiCell(0, 5, invoice.Note, "", "", false)
+	}
+
+	// Syarat dan ketentuan
+	if template.TermsText != "" {
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "B", style.fontSize)
+		pdf.Cell(0, 6, "SYARAT & KETENTUAN")
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "", style.fontSize)
+		pdf.MultiCell(0, 5, template.TermsText, "", "", false)
+	}
+
+	// Rekening pembayaran
+	writePaymentInfo(pdf, issuer)
+
+	// Penutup dari template, misalnya ucapan terima kasih
+	if template.FooterText != "" {
+		pdf.Ln(14)
+		pdf.SetFont(fontFamily, "B", style.fontSize)
+		pdf.MultiCell(0, 6, template.FooterText, "", "", false)
+	}
+	pdf.Ln(4)
+	writeFooterText(pdf, issuer)
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,161 @@
+package pdf
+
+import (
+	"github.com/sholllll662/invoice-backend/models"
+)
+
+// invoiceTable mencetak tabel item yang bisa terpotong ke beberapa halaman.
+// Header tabel dicetak ulang di setiap halaman baru dan teks panjang dibungkus
+// ke beberapa baris di dalam kolomnya.
+type invoiceTable struct {
+	pdf        *document
+	columns    []invoiceColumn
+	style      invoiceStyle
+	accent     [3]int
+	lineHeight float64
+	padding    float64
+}
+
+func newInvoiceTable(pdf *document, columns []invoiceColumn, style invoiceStyle, accent [3]int) *invoiceTable {
+	// Tinggi satu baris teks sekitar setengah ukuran font dalam mm; sisa
+	// rowHeight menjadi jarak atas dan bawah
+	lineHeight := style.fontSize * 0.5
+	return &invoiceTable{
+		pdf:        pdf,
+		columns:    columns,
+		style:      style,
+		accent:     accent,
+		lineHeight: lineHeight,
+		padding:    max(style.rowHeight-lineHeight, 0) / 2,
+	}
+}
+
+func (t *invoiceTable) align(column invoiceColumn) string {
+	switch column.key {
+	case models.ColumnDescription:
+		return "L"
+	case models.ColumnQuantity, models.ColumnTax:
+		return "C"
+	}
+	return t.style.moneyAlign
+}
+
+// totalWidth adalah lebar kolom terakhir (total), dipakai untuk meratakan baris ringkasan
+func (t *invoiceTable) totalWidth() float64 {
+	return t.columns[len(t.columns)-1].width
+}
+
+func (t *invoiceTable) header() {
+	pdf := t.pdf
+	pdf.SetFont(fontFamily, "B", t.style.fontSize)
+	if t.style.headerFill {
+		pdf.SetFillColor(t.accent[0], t.accent[1], t.accent[2])
+		pdf.SetTextColor(255, 255, 255)
+	}
+	for _, column := range t.columns {
+		pdf.CellFormat(column.width, t.style.rowHeight, column.title, "0", 0, t.align(column), t.style.headerFill, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont(fontFamily, "", t.style.fontSize)
+}
+
+// fits menandakan sisa halaman masih cukup untuk setinggi height
+func (t *invoiceTable) fits(height float64) bool {
+	_, pageHeight := t.pdf.GetPageSize()
+	_, bottom := t.pdf.GetAutoPageBreak()
+	return t.pdf.GetY()+height <= pageHeight-bottom
+}
+
+// ensureSpace pindah ke halaman baru dan mencetak ulang header tabel jika
+// sisa halaman kurang dari height
+func (t *invoiceTable) ensureSpace(height float64) {
+	if t.fits(height) {
+		return
+	}
+	t.pdf.AddPage()
+	t.header()
+}
+
+// wrap membungkus isi setiap kolom sesuai lebarnya
+func (t *invoiceTable) wrap(values []string) ([][]string, float64) {
+	lines := make([][]string, len(values))
+	count := 1
+	for i, value := range values {
+		lines[i] = t.pdf.SplitText(value, t.columns[i].width)
+		count = max(count, len(lines[i]))
+	}
+	return lines, float64(count)*t.lineHeight + 2*t.padding
+}
+
+// row mencetak satu baris tabel dengan tinggi menyesuaikan kolom yang paling banyak barisnya
+func (t *invoiceTable) row(lines [][]string, height float64, border string) {
+	pdf := t.pdf
+	left, top := pdf.GetXY()
+
+	x := left
+	for i, column := range t.columns {
+		pdf.SetXY(x, top)
+		pdf.CellFormat(column.width, height, "", border, 0, "", t.style.rowFill, 0, "")
+		for j, line := range lines[i] {
+			pdf.SetXY(x, top+t.padding+float64(j)*t.lineHeight)
+			pdf.CellFormat(column.width, t.lineHeight, line, "", 0, t.align(column), false, 0, "")
+		}
+		x += column.width
+	}
+	pdf.SetXY(left, top+height)
+}
+
+// items mencetak seluruh item. Tanpa kolom diskon, diskon baris dicetak di
+// baris tersendiri yang selalu satu halaman dengan itemnya.
+func (t *invoiceTable) items(invoice models.Invoice) {
+	pdf := t.pdf
+	discountRows := !hasColumn(t.columns, models.ColumnDiscount)
+	discountHeight := t.style.rowHeight * 0.8
+
+	t.header()
+	pdf.SetFillColor(230, 230, 230)
+	for i, item := range invoice.Items {
+		border := "B"
+		if i == len(invoice.Items)-1 {
+			border = "" // Tidak ada garis untuk baris terakhir
+		}
+
+		values := make([]string, len(t.columns))
+		for c, column := range t.columns {
+			values[c] = column.value(item)
+		}
+		lines, height := t.wrap(values)
+
+		withDiscount := discountRows && item.DiscountAmount > 0
+		if withDiscount {
+			t.ensureSpace(height + discountHeight)
+		} else {
+			t.ensureSpace(height)
+		}
+		// Warna isi bisa berubah oleh header di halaman baru
+		pdf.SetFillColor(230, 230, 230)
+
+		// Garis bawah pindah ke baris diskon jika item punya diskon
+		itemBorder := border
+		if withDiscount {
+			itemBorder = ""
+		}
+		t.row(lines, height, itemBorder)
+
+		if withDiscount {
+			totalWidth := t.totalWidth()
+			pdf.CellFormat(invoiceTableWidth-totalWidth, discountHeight, "    "+discountLabel(item.DiscountType, item.DiscountValue), border, 0, "", t.style.rowFill, 0, "")
+			pdf.CellFormat(totalWidth, discountHeight, "-"+FormatMoney(item.DiscountAmount, invoice.Currency), border, 1, t.style.moneyAlign, t.style.rowFill, 0, "")
+		}
+	}
+}
+
+func hasColumn(columns []invoiceColumn, key string) bool {
+	for _, column := range columns {
+		if column.key == key {
+			return true
+		}
+	}
+	return false
+}
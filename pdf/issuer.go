@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/sholllll662/invoice-backend/models"
+)
+
+const logoImageName = "logo"
+
+// logoImageType mengubah content type logo menjadi jenis gambar gofpdf
+func logoImageType(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return "PNG"
+	case "image/jpeg":
+		return "JPG"
+	}
+	return ""
+}
+
+// ValidateLogo memastikan logo PNG atau JPEG bisa dipakai oleh gofpdf, misalnya
+// PNG interlaced tidak didukung
+func ValidateLogo(data []byte, contentType string) error {
+	imageType := logoImageType(contentType)
+	if imageType == "" {
+		return errors.New("logo harus berupa gambar PNG atau JPEG")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.RegisterImageOptionsReader(logoImageName, gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if pdf.Err() {
+		return errors.New("gambar logo tidak bisa dibaca: " + pdf.Error().Error())
+	}
+	return nil
+}
+
+// writeIssuer mencetak data usaha penerbit di kiri atas dengan logo di kanan
+// atau kiri atas sesuai logoPosition
+func writeIssuer(pdf *document, issuer models.BusinessProfile, logoPosition string) {
+	left, _, right, _ := pdf.GetMargins()
+	top := pdf.GetY()
+	logoBottom := top
+
+	if len(issuer.Logo) > 0 && logoPosition != models.LogoNone {
+		options := gofpdf.ImageOptions{ImageType: logoImageType(issuer.LogoContentType)}
+		info := pdf.RegisterImageOptionsReader(logoImageName, options, bytes.NewReader(issuer.Logo))
+		if pdf.Ok() && info != nil {
+			// Logo dibatasi 40x20 mm dengan perbandingan asli
+			w, h := 40.0, 40.0*info.Height()/info.Width()
+			if h > 20 {
+				w, h = 20*info.Width()/info.Height(), 20
+			}
+			x := left
+			if logoPosition == models.LogoLeft {
+				// Teks penerbit digeser ke kanan logo
+				pdf.SetLeftMargin(left + w + 5)
+			} else {
+				pageW, _ := pdf.GetPageSize()
+				x = pageW - right - w
+			}
+			pdf.ImageOptions(logoImageName, x, top, w, h, false, options, 0, "")
+			logoBottom = top + h
+		}
+	}
+
+	pdf.SetY(top)
+	pdf.SetFont(fontFamily, "B", 14)
+	pdf.Cell(120, 7, issuer.LegalName)
+	pdf.Ln(7)
+
+	pdf.SetFont(fontFamily, "", 10)
+	if issuer.Address != "" {
+		pdf.MultiCell(120, 5, issuer.Address, "", "", false)
+	}
+	var contact []string
+	for _, value := range []string{issuer.Phone, issuer.Email} {
+		if value != "" {
+			contact = append(contact, value)
+		}
+	}
+	if len(contact) > 0 {
+		pdf.Cell(120, 5, strings.Join(contact, " | "))
+		pdf.Ln(5)
+	}
+	if issuer.TaxID != "" {
+		pdf.Cell(120, 5, "NPWP: "+issuer.TaxID)
+		pdf.Ln(5)
+	}
+
+	pdf.SetLeftMargin(left)
+	if pdf.GetY() < logoBottom {
+		pdf.SetY(logoBottom)
+	}
+	pdf.Ln(6)
+}
+
+// writeRecipient mencetak client penerima di kiri dan tanggal dokumen di kanan
+func writeRecipient(pdf *document, client models.Client, dateLabel, date string) {
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.Cell(30, 6, "KEPADA :")
+	pdf.Cell(90, 6, "")
+	pdf.Cell(30, 6, dateLabel)
+	pdf.Ln(6)
+
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.Cell(120, 6, client.Nama)
+	pdf.Cell(60, 6, date)
+	pdf.Ln(6)
+
+	for _, value := range []string{client.Email, client.NoTlp} {
+		if value != "" {
+			pdf.Cell(120, 6, value)
+			pdf.Ln(6)
+		}
+	}
+	pdf.Ln(4)
+}
+
+// writePaymentInfo mencetak rekening tujuan pembayaran jika sudah diisi
+func writePaymentInfo(pdf *document, issuer models.BusinessProfile) {
+	if !issuer.HasBankAccount() {
+		return
+	}
+
+	pdf.Ln(8)
+	pdf.SetFont(fontFamily, "B", 11)
+	pdf.Cell(0, 6, "PEMBAYARAN KE :")
+	pdf.Ln(6)
+	pdf.SetFont(fontFamily, "", 11)
+	for _, value := range []string{issuer.BankName, issuer.BankAccountNumber, issuer.BankAccountName} {
+		if value != "" {
+			pdf.Cell(0, 6, value)
+			pdf.Ln(6)
+		}
+	}
+}
+
+// writeFooterText mencetak teks penutup dari profil usaha
+func writeFooterText(pdf *document, issuer models.BusinessProfile) {
+	if issuer.FooterText != "" {
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "I", 10)
+		pdf.MultiCell(0, 5, issuer.FooterText, "", "", false)
+	}
+}
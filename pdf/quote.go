@@ -0,0 +1,140 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sholllll662/invoice-backend/models"
+)
+
+// RenderQuote menulis PDF quote ke w dengan tata letak yang sama seperti
+// invoice, ditambah masa berlaku penawaran
+func RenderQuote(w io.Writer, quote models.Quote, issuer models.BusinessProfile, client models.Client) error {
+	pdf := newDocument("P")
+	pdf.AddPage()
+
+	// Penerbit
+	writeIssuer(pdf, issuer, models.LogoRight)
+
+	// Judul
+	pdf.SetFont(fontFamily, "B", 20)
+	pdf.Cell(0, 10, "PENAWARAN HARGA")
+	pdf.Ln(12)
+
+	// Informasi KEPADA dan TANGGAL
+	issueDate, _ := time.Parse("2006-01-02", quote.IssueDate)
+	writeRecipient(pdf, client, "TANGGAL :", issueDate.Format("Monday, 02 January 2006"))
+
+	// Nomor quote dan masa berlaku
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.Cell(50, 6, "NO QUOTE :")
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.Cell(60, 6, quote.Number)
+	pdf.Ln(6)
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.Cell(50, 6, "BERLAKU SAMPAI :")
+	pdf.SetFont(fontFamily, "", 12)
+	expiryDate, _ := time.Parse("2006-01-02", quote.ExpiryDate)
+	pdf.Cell(60, 6, expiryDate.Format("02 January 2006"))
+	pdf.Ln(10)
+
+	// Header tabel item
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(80, 10, "KETERANGAN", "0", 0, "", false, 0, "")
+	pdf.CellFormat(40, 10, "HARGA", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(30, 10, "JML", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 10, "TOTAL", "0", 1, "C", false, 0, "")
+
+	pdf.SetFont(fontFamily, "", 12)
+	pdf.SetFillColor(230, 230, 230)
+	for i, item := range quote.Items {
+		border := "B"
+		if i == len(quote.Items)-1 {
+			border = ""
+		}
+
+		// Garis bawah pindah ke baris diskon jika item punya diskon
+		itemBorder := border
+		if item.DiscountAmount > 0 {
+			itemBorder = ""
+		}
+
+		pdf.CellFormat(80, 10, item.ItemName, itemBorder, 0, "", true, 0, "")
+		pdf.CellFormat(40, 10, FormatMoney(item.UnitPrice, quote.Currency), itemBorder, 0, "C", true, 0, "")
+		pdf.CellFormat(30, 10, strconv.Itoa(item.Quantity), itemBorder, 0, "C", true, 0, "")
+		pdf.CellFormat(40, 10, FormatMoney(item.TotalPrice, quote.Currency), itemBorder, 1, "C", true, 0, "")
+
+		if item.DiscountAmount > 0 {
+			pdf.CellFormat(150, 8, "    "+discountLabel(item.DiscountType, item.DiscountValue), border, 0, "", true, 0, "")
+			pdf.CellFormat(40, 8, "-"+FormatMoney(item.DiscountAmount, quote.Currency), border, 1, "C", true, 0, "")
+		}
+	}
+
+	// Subtotal
+	pdf.Ln(4)
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(120, 10, "", "0", 0, "", false, 0, "")
+	pdf.CellFormat(30, 10, "Sub Total", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 10, FormatMoney(quote.Subtotal, quote.Currency), "0", 1, "C", false, 0, "")
+
+	// Diskon tingkat quote
+	pdf.SetFont(fontFamily, "", 12)
+	if quote.DiscountAmount > 0 {
+		pdf.CellFormat(110, 8, "", "0", 0, "", false, 0, "")
+		pdf.CellFormat(40, 8, discountLabel(quote.DiscountType, quote.DiscountValue), "0", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 8, "-"+FormatMoney(quote.DiscountAmount, quote.Currency), "0", 1, "C", false, 0, "")
+	}
+
+	// Ringkasan pajak per tarif
+	for _, tax := range quote.TaxSummaries() {
+		label := fmt.Sprintf("%s %s%%", tax.Name, humanize.Ftoa(tax.Rate))
+		if tax.Inclusive {
+			label += " (termasuk)"
+		}
+		pdf.CellFormat(110, 8, "", "0", 0, "", false, 0, "")
+		pdf.CellFormat(40, 8, label, "0", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 8, FormatMoney(tax.Amount, quote.Currency), "0", 1, "C", false, 0, "")
+	}
+
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(120, 10, "", "0", 0, "", false, 0, "")
+	pdf.CellFormat(30, 10, "Total", "0", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 10, FormatMoney(quote.Amount, quote.Currency), "0", 1, "C", false, 0, "")
+
+	if quote.Note != "" {
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "B", 12)
+		pdf.Cell(0, 6, "CATATAN :")
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "", 12)
+		pdf.MultiCell(190, 6, quote.Note, "", "", false)
+	}
+
+	// Status selain Draft dan Terkirim dicap di PDF
+	switch quote.Status {
+	case models.QuoteStatusAccepted, models.QuoteStatusDeclined, models.QuoteStatusExpired:
+		pdf.Ln(10)
+		pdf.SetFont(fontFamily, "B", 16)
+		if quote.Status == models.QuoteStatusAccepted {
+			pdf.SetTextColor(0, 140, 0)
+		} else {
+			pdf.SetTextColor(200, 0, 0)
+		}
+		pdf.Cell(0, 10, strings.ToUpper(quote.Status))
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	writePaymentInfo(pdf, issuer)
+
+	pdf.Ln(20)
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.Cell(0, 10, issuer.LegalName)
+	pdf.Ln(10)
+	writeFooterText(pdf, issuer)
+
+	return pdf.Output(w)
+}
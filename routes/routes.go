@@ -14,20 +14,112 @@ func RegisterRoutes(router *gin.Engine) {
 		api.POST("/login", controller.Login)
 	}
 
+	// Tautan publik invoice, cukup token tanpa header Authorization
+	public := router.Group("/public")
+	{
+		public.GET("/invoices/:token", controller.GetPublicInvoice)
+		public.GET("/invoices/:token/pdf", controller.GetPublicInvoicePDF)
+	}
+
+	// Portal client, login terpisah dari akun User
+	portal := router.Group("/portal")
+	{
+		portal.POST("/activate", controller.PortalActivate)
+		portal.POST("/login", controller.PortalLogin)
+	}
+
+	portalProtected := router.Group("/portal")
+	portalProtected.Use(middlewares.ClientAuthMiddleware())
+	{
+		portalProtected.GET("/profile", controller.GetPortalProfile)
+		portalProtected.GET("/invoices", controller.GetPortalInvoices)
+		portalProtected.GET("/invoices/:id", controller.GetPortalInvoiceByID)
+		portalProtected.GET("/invoices/:id/pdf", controller.GetPortalInvoicePDF)
+	}
+
 	protected := router.Group("/api")
 	protected.Use(middlewares.AuthMiddleware())
 	{
 		protected.GET("/protected", controller.ProtectedEndpoint)
 		protected.POST("/clients", controller.CreateClient)
 		protected.GET("/clients", controller.GetClients)
+		protected.POST("/clients/import", controller.ImportClients)
 		protected.GET("/profile", middlewares.AuthMiddleware(), controller.GetProfile)
+		protected.GET("/settings", controller.GetSettings)
+		protected.PUT("/settings", controller.UpdateSettings)
+		protected.GET("/business-profile", controller.GetBusinessProfile)
+		protected.PUT("/business-profile", controller.UpdateBusinessProfile)
+		protected.GET("/business-profile/logo", controller.GetBusinessProfileLogo)
+		protected.PUT("/business-profile/logo", controller.UploadBusinessProfileLogo)
+		protected.DELETE("/business-profile/logo", controller.DeleteBusinessProfileLogo)
+		protected.GET("/invoice-templates", controller.GetInvoiceTemplates)
+		protected.POST("/invoice-templates", controller.CreateInvoiceTemplate)
+		protected.POST("/invoice-templates/preview", controller.PreviewInvoiceTemplateDraft)
+		protected.GET("/invoice-templates/:id", controller.GetInvoiceTemplateByID)
+		protected.PUT("/invoice-templates/:id", controller.UpdateInvoiceTemplate)
+		protected.DELETE("/invoice-templates/:id", controller.DeleteInvoiceTemplate)
+		protected.GET("/invoice-templates/:id/preview", controller.PreviewInvoiceTemplate)
+		protected.POST("/recurring-invoices", controller.CreateRecurringInvoice)
+		protected.GET("/recurring-invoices", controller.GetRecurringInvoices)
+		protected.GET("/recurring-invoices/:id", controller.GetRecurringInvoiceByID)
+		protected.PUT("/recurring-invoices/:id", controller.UpdateRecurringInvoice)
+		protected.DELETE("/recurring-invoices/:id", controller.DeleteRecurringInvoice)
+		protected.GET("/exchange-rates", controller.GetExchangeRates)
+		protected.GET("/search", controller.Search)
+		protected.GET("/reports/summary", controller.GetReportSummary)
+		protected.GET("/reports/aging", controller.GetAgingReport)
+		protected.GET("/reports/aging/pdf", controller.ExportAgingReportPDF)
+		protected.POST("/tax-rates", controller.CreateTaxRate)
+		protected.GET("/tax-rates", controller.GetTaxRates)
+		protected.PUT("/tax-rates/:id", controller.UpdateTaxRate)
+		protected.DELETE("/tax-rates/:id", controller.DeleteTaxRate)
+		protected.POST("/catalog-items", controller.CreateCatalogItem)
+		protected.GET("/catalog-items", controller.GetCatalogItems)
+		protected.GET("/catalog-items/:id", controller.GetCatalogItemByID)
+		protected.PUT("/catalog-items/:id", controller.UpdateCatalogItem)
+		protected.DELETE("/catalog-items/:id", controller.DeleteCatalogItem)
+		protected.POST("/reminder-rules", controller.CreateReminderRule)
+		protected.GET("/reminder-rules", controller.GetReminderRules)
+		protected.PUT("/reminder-rules/:id", controller.UpdateReminderRule)
+		protected.DELETE("/reminder-rules/:id", controller.DeleteReminderRule)
 		protected.PUT("/clients/:id", controller.UpdateClient)
 		protected.DELETE("/clients/:id", controller.DeleteClient)
+		protected.POST("/clients/:id/portal-invite", controller.InviteClientToPortal)
+		protected.DELETE("/clients/:id/portal-access", controller.RevokeClientPortalAccess)
+		protected.POST("/credit-notes", controller.CreateCreditNote)
+		protected.GET("/credit-notes", controller.GetCreditNotes)
+		protected.GET("/credit-notes/:id", controller.GetCreditNoteByID)
+		protected.GET("/credit-notes/:id/pdf", controller.ExportCreditNotePDF)
+		protected.POST("/credit-notes/:id/allocations", controller.AllocateCreditNote)
+		protected.POST("/credit-notes/:id/refunds", controller.RefundCreditNote)
+		protected.POST("/credit-notes/:id/void", controller.VoidCreditNote)
+		protected.POST("/quotes", controller.CreateQuote)
+		protected.GET("/quotes", controller.GetQuotes)
+		protected.GET("/quotes/:id", controller.GetQuoteByID)
+		protected.PUT("/quotes/:id", controller.UpdateQuote)
+		protected.DELETE("/quotes/:id", controller.DeleteQuote)
+		protected.GET("/quotes/:id/pdf", controller.ExportQuotePDF)
+		protected.POST("/quotes/:id/send", controller.SendQuote)
+		protected.POST("/quotes/:id/accept", controller.AcceptQuote)
+		protected.POST("/quotes/:id/decline", controller.DeclineQuote)
+		protected.POST("/quotes/:id/convert", controller.ConvertQuote)
 		protected.POST("/invoices", controller.CreateInvoice)
 		protected.GET("/invoices", middlewares.AuthMiddleware(), controller.GetInvoices)
+		protected.GET("/invoices/export", controller.ExportInvoices)
 		protected.GET("/invoices/:id", middlewares.AuthMiddleware(), controller.GetInvoiceByID)
 		protected.PUT("/invoices/:id", middlewares.AuthMiddleware(), controller.UpdateInvoiceByID)
 		protected.DELETE("/invoices/:id", middlewares.AuthMiddleware(), controller.DeleteInvoiceByID)
 		protected.GET("/invoices/:id/pdf", middlewares.AuthMiddleware(), controller.ExportInvoicePDF)
+		protected.POST("/invoices/:id/send", controller.SendInvoice)
+		protected.GET("/invoices/:id/emails", controller.GetInvoiceEmails)
+		protected.POST("/invoices/:id/share", controller.CreateInvoiceShareLink)
+		protected.GET("/invoices/:id/share", controller.GetInvoiceShareLinks)
+		protected.DELETE("/invoices/:id/share/:linkId", controller.RevokeInvoiceShareLink)
+		protected.POST("/invoices/:id/void", controller.VoidInvoice)
+		protected.POST("/invoices/:id/cancel", controller.CancelInvoice)
+		protected.GET("/invoices/:id/history", controller.GetInvoiceHistory)
+		protected.POST("/invoices/:id/payments", controller.CreateInvoicePayment)
+		protected.GET("/invoices/:id/payments", controller.GetInvoicePayments)
+		protected.DELETE("/invoices/:id/payments/:paymentId", controller.DeleteInvoicePayment)
 	}
 }
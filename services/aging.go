@@ -0,0 +1,96 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// BuildAgingReport menghitung umur piutang per client pada tanggal asOf
+// (YYYY-MM-DD). Sisa tagihan dihitung ulang dari pembayaran dan kredit yang
+// tercatat sampai tanggal tersebut, jadi invoice yang sekarang sudah lunas tetap
+// muncul jika saat itu belum lunas. Invoice Void tidak diikutkan.
+func BuildAgingReport(tx *gorm.DB, userID uint, asOf string) (models.AgingReport, error) {
+	setting, err := LoadUserSetting(tx, userID)
+	if err != nil {
+		return models.AgingReport{}, err
+	}
+	report := models.AgingReport{AsOf: asOf, BaseCurrency: setting.BaseCurrency, Clients: []models.AgingClient{}}
+
+	date, err := time.Parse(dateLayout, asOf)
+	if err != nil {
+		return report, &ValidationError{Message: "format as_of harus YYYY-MM-DD"}
+	}
+
+	// Alokasi kredit hanya punya waktu pencatatan, tanggalnya dibaca di zona
+	// waktu user supaya sama dengan asOf
+	var rows []struct {
+		ID           uint
+		Number       string
+		ClientID     uint
+		ClientName   string
+		IssueDate    string
+		DueDate      string
+		Currency     string
+		ExchangeRate float64
+		Amount       models.Money
+		Paid         models.Money
+		Credited     models.Money
+	}
+	err = tx.Model(&models.Invoice{}).
+		Joins("LEFT JOIN clients ON clients.id = invoices.client_id").
+		Select(`invoices.id, invoices.number, invoices.client_id, COALESCE(clients.nama, '') AS client_name,
+			invoices.issue_date, invoices.due_date, invoices.currency, invoices.exchange_rate, invoices.amount,
+			COALESCE((SELECT SUM(p.amount) FROM invoice_payments AS p
+				WHERE p.invoice_id = invoices.id AND p.payment_date <= ?), 0)::bigint AS paid,
+			COALESCE((SELECT SUM(a.amount) FROM credit_note_allocations AS a
+				WHERE a.invoice_id = invoices.id AND (a.created_at AT TIME ZONE ?)::date <= ?), 0)::bigint AS credited`,
+			asOf, setting.Timezone, asOf).
+		Where("invoices.user_id = ? AND invoices.status IN ?", userID, issuedStatuses).
+		Where("invoices.issue_date <= ? AND invoices.base_currency = ?", asOf, setting.BaseCurrency).
+		Order("client_name, invoices.client_id, invoices.due_date, invoices.id").
+		Scan(&rows).Error
+	if err != nil {
+		return report, err
+	}
+
+	if err := tx.Model(&models.Invoice{}).
+		Where("user_id = ? AND status IN ? AND issue_date <= ?", userID, issuedStatuses, asOf).
+		Where("base_currency <> ?", setting.BaseCurrency).
+		Count(&report.ExcludedCount).Error; err != nil {
+		return report, err
+	}
+
+	for _, row := range rows {
+		balance := row.Amount - row.Paid - row.Credited
+		if balance <= 0 {
+			continue
+		}
+
+		days, _ := DaysPastDue(models.Invoice{DueDate: row.DueDate}, date)
+		line := models.AgingInvoice{
+			InvoiceID:   row.ID,
+			Number:      row.Number,
+			IssueDate:   row.IssueDate,
+			DueDate:     row.DueDate,
+			DaysPastDue: max(days, 0),
+			Currency:    row.Currency,
+			Balance:     balance,
+			BaseBalance: balance.Convert(row.ExchangeRate),
+		}
+
+		// Baris sudah urut per client, jadi cukup cek client terakhir
+		n := len(report.Clients)
+		if n == 0 || report.Clients[n-1].ClientID != row.ClientID {
+			report.Clients = append(report.Clients, models.AgingClient{ClientID: row.ClientID, Name: row.ClientName})
+			n++
+		}
+		client := &report.Clients[n-1]
+		client.Buckets.Add(line.DaysPastDue, line.BaseBalance)
+		client.Invoices = append(client.Invoices, line)
+		report.Totals.Add(line.DaysPastDue, line.BaseBalance)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// LoadBusinessProfile mengambil profil usaha user apa adanya, kosong jika belum dibuat
+func LoadBusinessProfile(tx *gorm.DB, userID uint) (models.BusinessProfile, error) {
+	profile := models.BusinessProfile{UserID: userID}
+	err := tx.Where("user_id = ?", userID).Limit(1).Find(&profile).Error
+	profile.HasLogo = len(profile.Logo) > 0
+	return profile, err
+}
+
+// LoadIssuer mengambil profil usaha untuk dicetak sebagai penerbit dokumen,
+// dengan nama dan email akun sebagai cadangan
+func LoadIssuer(tx *gorm.DB, userID uint) (models.BusinessProfile, error) {
+	profile, err := LoadBusinessProfile(tx, userID)
+	if err != nil {
+		return profile, err
+	}
+
+	var user models.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		return profile, err
+	}
+	profile.ApplyUserDefaults(user)
+	return profile, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClientInviteDays adalah masa berlaku undangan portal client
+const ClientInviteDays = 7
+
+// ErrClientInviteInvalid dipakai untuk semua undangan yang tidak bisa dipakai
+var ErrClientInviteInvalid = errors.New("undangan portal tidak valid atau sudah kedaluwarsa")
+
+// InviteClient membuat atau memperbarui akun portal client lalu mengembalikan
+// tautan undangan. Undangan sebelumnya otomatis tidak berlaku, dan akses yang
+// pernah dicabut dibuka kembali setelah undangan dipakai.
+func InviteClient(tx *gorm.DB, client models.Client) (models.ClientAccount, string, error) {
+	var account models.ClientAccount
+	if client.Email == "" {
+		return account, "", &ValidationError{Message: "client belum memiliki alamat email"}
+	}
+
+	if err := tx.Where("client_id = ?", client.ID).Limit(1).Find(&account).Error; err != nil {
+		return account, "", err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return account, "", err
+	}
+	expiresAt := time.Now().AddDate(0, 0, ClientInviteDays).Truncate(time.Second)
+
+	account.UserID = client.UserID
+	account.ClientID = client.ID
+	account.Email = client.Email
+	account.InviteNonce = hex.EncodeToString(nonce)
+	account.InviteExpiresAt = &expiresAt
+	if err := tx.Save(&account).Error; err != nil {
+		return account, "", err
+	}
+
+	token, err := utils.GenerateClientInviteToken(account.ID, client.ID, account.InviteNonce, expiresAt)
+	if err != nil {
+		return account, "", err
+	}
+	inviteURL := strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/") + "/portal/activate?token=" + token
+	return account, inviteURL, nil
+}
+
+// SendClientInvite mengirim email undangan portal ke client
+func SendClientInvite(client models.Client, sender models.User, inviteURL string) error {
+	return utils.SendMail(utils.Mail{
+		To:      client.Email,
+		Subject: fmt.Sprintf("Undangan portal invoice dari %s", sender.Name),
+		Body: fmt.Sprintf(`Yth. %s,
+
+%s mengundang Anda untuk melihat seluruh invoice dan sisa tagihan Anda secara online.
+Buat password portal Anda melalui tautan berikut (berlaku %d hari):
+
+%s
+
+Terima kasih,
+%s`, client.Nama, sender.Name, ClientInviteDays, inviteURL, sender.Name),
+	})
+}
+
+// ActivateClientAccount memakai token undangan untuk mengatur password akun portal.
+// Token undangan hanya bisa dipakai sekali.
+func ActivateClientAccount(tx *gorm.DB, token string, password string) (models.ClientAccount, error) {
+	var account models.ClientAccount
+
+	claims, err := utils.ValidateClientToken(token, utils.ClientInviteAudience)
+	if err != nil {
+		return account, ErrClientInviteInvalid
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, claims.AccountID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return account, ErrClientInviteInvalid
+		}
+		return account, err
+	}
+
+	now := time.Now()
+	if account.InviteNonce == "" ||
+		subtle.ConstantTimeCompare([]byte(account.InviteNonce), []byte(claims.Nonce)) != 1 ||
+		account.InviteExpiresAt == nil || now.After(*account.InviteExpiresAt) {
+		return account, ErrClientInviteInvalid
+	}
+
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return account, err
+	}
+
+	account.Password = hashed
+	account.ActivatedAt = &now
+	account.DisabledAt = nil
+	account.InviteNonce = ""
+	account.InviteExpiresAt = nil
+
+	err = tx.Save(&account).Error
+	return account, err
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateCreditNote menerbitkan credit note untuk invoice asli. Baris yang merujuk
+// ke baris invoice memakai nama, harga bersih dan pajak baris tersebut. Jika
+// applyToInvoice, kredit langsung dipakai untuk mengurangi sisa tagihan invoice asli.
+func CreateCreditNote(tx *gorm.DB, note *models.CreditNote, applyToInvoice bool, actorID uint) error {
+	if _, err := time.Parse(dateLayout, note.IssueDate); err != nil {
+		return &ValidationError{Message: "format issue_date harus YYYY-MM-DD"}
+	}
+	if len(note.Items) == 0 {
+		return &ValidationError{Message: "credit note harus memiliki minimal satu item"}
+	}
+
+	var invoice models.Invoice
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items").
+		Where("id = ? AND user_id = ?", note.InvoiceID, note.UserID).
+		First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ValidationError{Message: "invoice tidak ditemukan"}
+		}
+		return err
+	}
+	if !models.IsPayable(invoice.Status) && invoice.Status != models.StatusPaid {
+		return &ValidationError{Message: fmt.Sprintf("invoice berstatus %s tidak bisa dibuatkan credit note", invoice.Status)}
+	}
+
+	if err := resolveCreditNoteItems(tx, note, invoice); err != nil {
+		return err
+	}
+
+	note.ClientID = invoice.ClientID
+	note.Currency = invoice.Currency
+	note.ExchangeRate = invoice.ExchangeRate
+	note.BaseCurrency = invoice.BaseCurrency
+	note.Status = models.CreditNoteStatusOpen
+	note.CalculateTotals()
+	note.UpdateBalance(0, 0)
+
+	if note.Amount <= 0 {
+		return &ValidationError{Message: "total credit note harus lebih dari 0"}
+	}
+
+	// Total seluruh credit note tidak boleh melebihi nilai invoice asli
+	var credited models.Money
+	if err := tx.Model(&models.CreditNote{}).
+		Where("invoice_id = ? AND status <> ?", invoice.ID, models.CreditNoteStatusVoid).
+		Select("COALESCE(SUM(amount), 0)::bigint").
+		Scan(&credited).Error; err != nil {
+		return err
+	}
+	if credited+note.Amount > invoice.Amount {
+		return &ValidationError{Message: fmt.Sprintf(
+			"total credit note melebihi nilai invoice, sisa yang bisa dikredit %s %s",
+			invoice.Currency, invoice.Amount-credited)}
+	}
+
+	number, err := NextCreditNoteNumber(tx, note.UserID, note.IssueDate)
+	if err != nil {
+		return err
+	}
+	note.Number = number
+
+	if err := tx.Create(note).Error; err != nil {
+		return err
+	}
+
+	if applyToInvoice && models.IsPayable(invoice.Status) && invoice.Balance > 0 {
+		return AllocateCreditNote(tx, note, &invoice, min(note.Balance, invoice.Balance), actorID)
+	}
+	return nil
+}
+
+// resolveCreditNoteItems melengkapi baris credit note dari baris invoice asli
+// atau dari tarif pajak user untuk baris bebas
+func resolveCreditNoteItems(tx *gorm.DB, note *models.CreditNote, invoice models.Invoice) error {
+	lines := map[uint]models.InvoiceItem{}
+	for _, line := range invoice.Items {
+		lines[line.ID] = line
+	}
+
+	// Quantity yang sudah dikredit per baris invoice oleh credit note sebelumnya
+	var previous []struct {
+		InvoiceItemID uint
+		Quantity      int
+	}
+	if err := tx.Model(&models.CreditNoteItem{}).
+		Joins("JOIN credit_notes ON credit_notes.id = credit_note_items.credit_note_id").
+		Where("credit_notes.invoice_id = ? AND credit_notes.status <> ? AND credit_notes.deleted_at IS NULL",
+			invoice.ID, models.CreditNoteStatusVoid).
+		Where("credit_note_items.invoice_item_id IS NOT NULL").
+		Select("credit_note_items.invoice_item_id, SUM(credit_note_items.quantity) AS quantity").
+		Group("credit_note_items.invoice_item_id").
+		Scan(&previous).Error; err != nil {
+		return err
+	}
+	creditedQty := map[uint]int{}
+	for _, p := range previous {
+		creditedQty[p.InvoiceItemID] = p.Quantity
+	}
+
+	var free []models.InvoiceItem
+	var freeIdx []int
+	for i := range note.Items {
+		item := &note.Items[i]
+		if item.Quantity <= 0 {
+			return &ValidationError{Message: "quantity item credit note harus lebih dari 0"}
+		}
+		if item.UnitPrice < 0 {
+			return &ValidationError{Message: "harga item credit note tidak boleh negatif"}
+		}
+
+		if item.InvoiceItemID == nil {
+			if item.ItemName == "" || item.UnitPrice == 0 {
+				return &ValidationError{Message: "item_name dan unit_price wajib diisi untuk item tanpa invoice_item_id"}
+			}
+			free = append(free, models.InvoiceItem{TaxRateIDs: item.TaxRateIDs})
+			freeIdx = append(freeIdx, i)
+			continue
+		}
+
+		line, ok := lines[*item.InvoiceItemID]
+		if !ok {
+			return &ValidationError{Message: fmt.Sprintf("item invoice %d tidak ditemukan di invoice ini", *item.InvoiceItemID)}
+		}
+		creditedQty[line.ID] += item.Quantity
+		if creditedQty[line.ID] > line.Quantity {
+			return &ValidationError{Message: fmt.Sprintf("quantity %s yang dikredit melebihi quantity di invoice", line.ItemName)}
+		}
+
+		if item.ItemName == "" {
+			item.ItemName = line.ItemName
+		}
+		if item.UnitPrice == 0 {
+			item.UnitPrice = line.NetUnitPrice()
+		}
+		// Pajak mengikuti salinan tarif di invoice asli
+		item.Taxes = append([]models.ItemTax(nil), line.Taxes...)
+	}
+
+	if len(free) > 0 {
+		if err := ResolveItemTaxes(tx, note.UserID, free); err != nil {
+			return err
+		}
+		for i, idx := range freeIdx {
+			note.Items[idx].Taxes = free[i].Taxes
+		}
+	}
+	return nil
+}
+
+// AllocateCreditNote memakai sebagian kredit untuk mengurangi sisa tagihan invoice
+// client yang sama. note dan invoice harus sudah dikunci oleh pemanggil.
+func AllocateCreditNote(tx *gorm.DB, note *models.CreditNote, invoice *models.Invoice, amount models.Money, actorID uint) error {
+	switch {
+	case note.Status == models.CreditNoteStatusVoid:
+		return &ValidationError{Message: "credit note sudah di-void"}
+	case invoice.UserID != note.UserID || invoice.ClientID != note.ClientID:
+		return &ValidationError{Message: "kredit hanya bisa dipakai untuk invoice client yang sama"}
+	case invoice.Currency != note.Currency:
+		return &ValidationError{Message: fmt.Sprintf("mata uang invoice (%s) berbeda dengan credit note (%s)", invoice.Currency, note.Currency)}
+	case !models.IsPayable(invoice.Status):
+		return &ValidationError{Message: fmt.Sprintf("invoice berstatus %s tidak bisa menerima kredit", invoice.Status)}
+	case amount <= 0:
+		return &ValidationError{Message: "jumlah kredit harus lebih dari 0"}
+	case amount > note.Balance:
+		return &ValidationError{Message: "jumlah kredit melebihi sisa credit note"}
+	case amount > invoice.Balance:
+		return &ValidationError{Message: "jumlah kredit melebihi sisa tagihan invoice"}
+	}
+
+	allocation := models.CreditNoteAllocation{
+		CreditNoteID: note.ID,
+		InvoiceID:    invoice.ID,
+		Amount:       amount,
+		UserID:       actorID,
+	}
+	if err := tx.Create(&allocation).Error; err != nil {
+		return err
+	}
+
+	if err := SyncCreditNoteBalance(tx, note); err != nil {
+		return err
+	}
+	return SyncInvoiceBalance(tx, invoice, actorID)
+}
+
+// RefundCreditNote mengembalikan sebagian sisa kredit ke client
+func RefundCreditNote(tx *gorm.DB, note *models.CreditNote, refund *models.CreditNoteRefund) error {
+	if note.Status == models.CreditNoteStatusVoid {
+		return &ValidationError{Message: "credit note sudah di-void"}
+	}
+	if _, err := time.Parse(dateLayout, refund.RefundDate); err != nil {
+		return &ValidationError{Message: "format refund_date harus YYYY-MM-DD"}
+	}
+	if refund.Amount <= 0 || refund.Amount > note.Balance {
+		return &ValidationError{Message: fmt.Sprintf("jumlah refund harus di antara 0 dan sisa kredit %s", note.Balance)}
+	}
+
+	refund.CreditNoteID = note.ID
+	if err := tx.Create(refund).Error; err != nil {
+		return err
+	}
+	return SyncCreditNoteBalance(tx, note)
+}
+
+// VoidCreditNote membatalkan credit note yang belum di-refund. Kredit yang sudah
+// dipakai dilepas sehingga sisa tagihan invoice kembali seperti semula.
+func VoidCreditNote(tx *gorm.DB, note *models.CreditNote, actorID uint) error {
+	if note.Status == models.CreditNoteStatusVoid {
+		return &ValidationError{Message: "credit note sudah di-void"}
+	}
+	if note.AmountRefunded > 0 {
+		return &ValidationError{Message: "credit note yang sudah di-refund tidak bisa di-void"}
+	}
+
+	var allocations []models.CreditNoteAllocation
+	if err := tx.Where("credit_note_id = ?", note.ID).Find(&allocations).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("credit_note_id = ?", note.ID).Delete(&models.CreditNoteAllocation{}).Error; err != nil {
+		return err
+	}
+
+	note.Status = models.CreditNoteStatusVoid
+	if err := SyncCreditNoteBalance(tx, note); err != nil {
+		return err
+	}
+
+	for _, allocation := range allocations {
+		var invoice models.Invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, allocation.InvoiceID).Error; err != nil {
+			return err
+		}
+		if err := SyncInvoiceBalance(tx, &invoice, actorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncCreditNoteBalance menjumlahkan ulang kredit terpakai dan refund credit note
+func SyncCreditNoteBalance(tx *gorm.DB, note *models.CreditNote) error {
+	var applied, refunded models.Money
+	if err := tx.Model(&models.CreditNoteAllocation{}).
+		Where("credit_note_id = ?", note.ID).
+		Select("COALESCE(SUM(amount), 0)::bigint").
+		Scan(&applied).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.CreditNoteRefund{}).
+		Where("credit_note_id = ?", note.ID).
+		Select("COALESCE(SUM(amount), 0)::bigint").
+		Scan(&refunded).Error; err != nil {
+		return err
+	}
+
+	note.UpdateBalance(applied, refunded)
+	return tx.Model(note).Select("amount_applied", "amount_refunded", "balance", "status").Updates(note).Error
+}
+
+// InvoiceHasCreditNotes menandakan invoice sudah dikoreksi atau menerima kredit,
+// sehingga tidak boleh lagi diubah atau dihapus
+func InvoiceHasCreditNotes(tx *gorm.DB, invoiceID uint) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.CreditNote{}).Where("invoice_id = ?", invoiceID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err := tx.Model(&models.CreditNoteAllocation{}).Where("invoice_id = ?", invoiceID).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ApplyInvoiceCurrency menentukan mata uang dan kurs invoice. Urutannya: mata uang
+// dari request, mata uang default client, lalu mata uang dasar user. Kurs diambil
+// dari file kurs pada tanggal terbit kecuali diisi manual.
+func ApplyInvoiceCurrency(tx *gorm.DB, invoice *models.Invoice, currency string, manualRate float64) error {
+	var client models.Client
+	if err := tx.Where("id = ? AND user_id = ?", invoice.ClientID, invoice.UserID).First(&client).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &ValidationError{Message: "client tidak ditemukan"}
+		}
+		return err
+	}
+
+	setting, err := LoadUserSetting(tx, invoice.UserID)
+	if err != nil {
+		return err
+	}
+
+	if currency != "" {
+		invoice.Currency = utils.NormalizeCurrency(currency)
+		if invoice.Currency == "" {
+			return &ValidationError{Message: "kode mata uang harus 3 huruf, misalnya IDR atau USD"}
+		}
+	} else if client.Currency != "" {
+		invoice.Currency = client.Currency
+	} else {
+		invoice.Currency = setting.BaseCurrency
+	}
+	invoice.BaseCurrency = setting.BaseCurrency
+
+	if manualRate > 0 {
+		invoice.ExchangeRate = manualRate
+		return nil
+	}
+
+	issueDate, err := time.Parse("2006-01-02", invoice.IssueDate)
+	if err != nil {
+		return &ValidationError{Message: "format issue_date harus YYYY-MM-DD"}
+	}
+
+	rate, err := utils.ExchangeRate(invoice.Currency, invoice.BaseCurrency, issueDate)
+	if err != nil {
+		return &ValidationError{Message: err.Error()}
+	}
+	invoice.ExchangeRate = rate
+	return nil
+}
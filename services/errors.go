@@ -0,0 +1,25 @@
+package services
+
+// ValidationError menandakan input tidak valid, misalnya tarif pajak atau client
+// tidak ditemukan. Controller menerjemahkannya menjadi 400 Bad Request.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// DeliveryError menandakan email gagal dikirim ke server SMTP. Percobaannya
+// tetap tercatat di log email invoice.
+type DeliveryError struct {
+	Err error
+}
+
+func (e *DeliveryError) Error() string {
+	return "gagal mengirim email: " + e.Err.Error()
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// InvoiceOptions berisi input pembuatan invoice yang tidak disimpan apa adanya
+type InvoiceOptions struct {
+	Currency     string  // kosong = mata uang client / user
+	ExchangeRate float64 // 0 = ambil dari file kurs
+	ActorID      uint    // user yang membuat, 0 jika oleh sistem
+	Note         string  // catatan riwayat status awal
+	// KeepItemTaxes memakai pajak yang sudah ada di item (misalnya dari quote)
+	// alih-alih membaca ulang tarif dari TaxRateIDs
+	KeepItemTaxes bool
+}
+
+// PrepareInvoice memvalidasi input, melengkapi item dari katalog, menyalin tarif pajak, menentukan kurs lalu
+// menghitung ulang diskon, subtotal, pajak dan total invoice
+func PrepareInvoice(tx *gorm.DB, invoice *models.Invoice, opts InvoiceOptions) error {
+	// Tanggal terbit dipakai untuk penomoran dan kurs
+	if _, err := time.Parse("2006-01-02", invoice.IssueDate); err != nil {
+		return &ValidationError{Message: "format issue_date harus YYYY-MM-DD"}
+	}
+
+	if err := ResolveCatalogItems(tx, invoice.UserID, invoice.Items); err != nil {
+		return err
+	}
+
+	// Harga dicek setelah dilengkapi dari katalog, supaya total tidak pernah negatif
+	for _, item := range invoice.Items {
+		if item.Quantity <= 0 {
+			return &ValidationError{Message: "quantity item harus lebih dari 0"}
+		}
+		if item.UnitPrice < 0 {
+			return &ValidationError{Message: "harga item tidak boleh negatif"}
+		}
+	}
+
+	if invoice.PDFTemplate != "" {
+		if _, err := FindInvoiceTemplate(tx, invoice.UserID, invoice.PDFTemplate); err != nil {
+			return err
+		}
+	}
+
+	if !opts.KeepItemTaxes {
+		if err := ResolveItemTaxes(tx, invoice.UserID, invoice.Items); err != nil {
+			return err
+		}
+	}
+
+	if err := ApplyInvoiceCurrency(tx, invoice, opts.Currency, opts.ExchangeRate); err != nil {
+		return err
+	}
+
+	if err := invoice.ValidateDiscounts(); err != nil {
+		return &ValidationError{Message: err.Error()}
+	}
+
+	invoice.CalculateTotals()
+	invoice.UpdateBalance(invoice.AmountPaid)
+	return nil
+}
+
+// CreateInvoice menghitung total, memberi nomor lalu menyimpan invoice baru
+// berstatus Draft. Dipanggil di dalam transaksi supaya nomor invoice ikut
+// di-rollback bila gagal.
+func CreateInvoice(tx *gorm.DB, invoice *models.Invoice, opts InvoiceOptions) error {
+	invoice.Status = models.StatusDraft
+	invoice.AmountPaid = 0
+	if err := PrepareInvoice(tx, invoice, opts); err != nil {
+		return err
+	}
+
+	number, err := NextInvoiceNumber(tx, invoice.UserID, invoice.IssueDate)
+	if err != nil {
+		return err
+	}
+	invoice.Number = number
+
+	if err := tx.Create(invoice).Error; err != nil {
+		return err
+	}
+	return RecordInvoiceStatus(tx, invoice.ID, "", invoice.Status, opts.ActorID, opts.Note)
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/pdf"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+// InvoiceEmailData adalah data yang bisa dipakai di template subject dan isi email
+type InvoiceEmailData struct {
+	Invoice     models.Invoice
+	ClientName  string
+	SenderName  string
+	SenderEmail string
+	Amount      string // total dengan simbol mata uang, misalnya "Rp 1,500,000"
+	Balance     string // sisa tagihan
+	DueDate     string // misalnya "31 January 2025"
+	DaysOverdue int    // hari lewat jatuh tempo, 0 jika belum
+}
+
+func renderEmailTemplate(text string, data InvoiceEmailData) (string, error) {
+	tmpl, err := template.New("email").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// ValidateInvoiceEmailTemplate memastikan template bisa dirender, termasuk
+// nama field yang dipakai
+func ValidateInvoiceEmailTemplate(text string) error {
+	if _, err := renderEmailTemplate(text, InvoiceEmailData{}); err != nil {
+		return &ValidationError{Message: "template email tidak valid: " + err.Error()}
+	}
+	return nil
+}
+
+// invoiceMail menyimpan data yang dibutuhkan untuk mengirim email sebuah invoice
+type invoiceMail struct {
+	invoice  *models.Invoice
+	client   models.Client
+	issuer   models.BusinessProfile
+	template models.InvoiceTemplate
+	setting  models.UserSetting
+	data     InvoiceEmailData
+}
+
+// loadInvoiceMail memuat client, pengirim dan pengaturan user, sekaligus
+// memastikan invoice memang bisa dikirim ke client
+func loadInvoiceMail(tx *gorm.DB, invoice *models.Invoice) (*invoiceMail, error) {
+	if invoice.Status == models.StatusVoid || invoice.Status == models.StatusCancelled {
+		return nil, &ValidationError{
+			Message: fmt.Sprintf("invoice berstatus %s tidak bisa dikirim", invoice.Status),
+		}
+	}
+
+	m := &invoiceMail{invoice: invoice}
+	if err := tx.Where("id = ? AND user_id = ?", invoice.ClientID, invoice.UserID).First(&m.client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ValidationError{Message: "client invoice tidak ditemukan"}
+		}
+		return nil, err
+	}
+	if m.client.Email == "" {
+		return nil, &ValidationError{Message: "client belum memiliki alamat email"}
+	}
+
+	issuer, err := LoadIssuer(tx, invoice.UserID)
+	if err != nil {
+		return nil, err
+	}
+	m.issuer = issuer
+
+	if m.template, err = InvoiceTemplateFor(tx, *invoice); err != nil {
+		return nil, err
+	}
+
+	setting, err := LoadUserSetting(tx, invoice.UserID)
+	if err != nil {
+		return nil, err
+	}
+	m.setting = setting
+
+	if invoice.Items == nil {
+		if err := tx.Where("invoice_id = ?", invoice.ID).Find(&invoice.Items).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	m.data = InvoiceEmailData{
+		Invoice:     *invoice,
+		ClientName:  m.client.Nama,
+		SenderName:  m.issuer.LegalName,
+		SenderEmail: m.issuer.Email,
+		Amount:      pdf.FormatMoney(invoice.Amount, invoice.Currency),
+		Balance:     pdf.FormatMoney(invoice.Balance, invoice.Currency),
+		DueDate:     invoice.DueDate,
+	}
+	if due, err := time.Parse(dateLayout, invoice.DueDate); err == nil {
+		m.data.DueDate = due.Format("02 January 2006")
+	}
+	return m, nil
+}
+
+// render merender template dan PDF invoice menjadi email yang siap dikirim
+func (m *invoiceMail) render(subjectTemplate, bodyTemplate string) (utils.Mail, error) {
+	subject, err := renderEmailTemplate(subjectTemplate, m.data)
+	if err != nil {
+		return utils.Mail{}, &ValidationError{Message: "template subject email tidak valid: " + err.Error()}
+	}
+	body, err := renderEmailTemplate(bodyTemplate, m.data)
+	if err != nil {
+		return utils.Mail{}, &ValidationError{Message: "template isi email tidak valid: " + err.Error()}
+	}
+
+	var attachment bytes.Buffer
+	if err := pdf.RenderInvoice(&attachment, *m.invoice, m.issuer, m.client, m.template); err != nil {
+		return utils.Mail{}, err
+	}
+
+	return utils.Mail{
+		To:      m.client.Email,
+		Subject: subject,
+		Body:    body,
+		Attachments: []utils.MailAttachment{{
+			Filename:    InvoicePDFFilename(m.invoice),
+			ContentType: "application/pdf",
+			Data:        attachment.Bytes(),
+		}},
+	}, nil
+}
+
+// send merender template lalu mengirim email dengan PDF invoice terlampir.
+// Gagal kirim ke SMTP dikembalikan terpisah di sendErr supaya bisa dicatat.
+func (m *invoiceMail) send(subjectTemplate, bodyTemplate string) (subject string, sendErr error, err error) {
+	mail, err := m.render(subjectTemplate, bodyTemplate)
+	if err != nil {
+		return "", nil, err
+	}
+	return mail.Subject, utils.SendMail(mail), nil
+}
+
+// EmailInvoice merender PDF invoice lalu mengirimkannya ke email client.
+// Setiap percobaan kirim dicatat di log, termasuk yang gagal, sehingga log
+// harus ditulis di luar transaksi yang bisa di-rollback. Status invoice tidak
+// diubah di sini.
+func EmailInvoice(tx *gorm.DB, invoice *models.Invoice, userID uint) (models.InvoiceEmailLog, error) {
+	m, err := loadInvoiceMail(tx, invoice)
+	if err != nil {
+		return models.InvoiceEmailLog{}, err
+	}
+
+	subject, sendErr, err := m.send(m.setting.InvoiceEmailSubject, m.setting.InvoiceEmailBody)
+	if err != nil {
+		return models.InvoiceEmailLog{}, err
+	}
+
+	entry := models.InvoiceEmailLog{
+		InvoiceID: invoice.ID,
+		UserID:    userID,
+		Recipient: m.client.Email,
+		Subject:   subject,
+		Status:    models.EmailStatusSent,
+	}
+	if sendErr != nil {
+		entry.Status = models.EmailStatusFailed
+		entry.Error = sendErr.Error()
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return entry, err
+	}
+	if sendErr != nil {
+		return entry, &DeliveryError{Err: sendErr}
+	}
+	return entry, nil
+}
+
+// InvoicePDFFilename membuat nama file dari nomor invoice, "/" diganti "-"
+func InvoicePDFFilename(invoice *models.Invoice) string {
+	name := strings.NewReplacer("/", "-", "\\", "-", " ", "_").Replace(invoice.Number)
+	if name == "" {
+		name = fmt.Sprintf("invoice-%d", invoice.ID)
+	}
+	return name + ".pdf"
+}
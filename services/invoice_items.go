@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// taxOrder menentukan urutan perhitungan: inclusive, biasa, lalu compound
+func taxOrder(tax models.ItemTax) int {
+	switch {
+	case tax.Inclusive:
+		return 0
+	case tax.Compound:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// ResolveItemTaxes mengisi salinan pajak setiap item dari TaxRateIDs milik user
+func ResolveItemTaxes(tx *gorm.DB, userID uint, items []models.InvoiceItem) error {
+	var ids []uint
+	for _, item := range items {
+		ids = append(ids, item.TaxRateIDs...)
+	}
+
+	rates := map[uint]models.TaxRate{}
+	if len(ids) > 0 {
+		var found []models.TaxRate
+		if err := tx.Where("user_id = ? AND id IN ?", userID, ids).Find(&found).Error; err != nil {
+			return err
+		}
+		for _, rate := range found {
+			rates[rate.ID] = rate
+		}
+	}
+
+	for i := range items {
+		items[i].Taxes = nil
+		seen := map[uint]bool{}
+
+		for _, id := range items[i].TaxRateIDs {
+			rate, ok := rates[id]
+			if !ok {
+				return &ValidationError{Message: fmt.Sprintf("tarif pajak %d tidak ditemukan", id)}
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			items[i].Taxes = append(items[i].Taxes, models.ItemTax{
+				TaxRateID: rate.ID,
+				Name:      rate.Name,
+				Rate:      rate.Rate,
+				Inclusive: rate.Inclusive,
+				Compound:  rate.Compound,
+			})
+		}
+
+		sort.SliceStable(items[i].Taxes, func(a, b int) bool {
+			return taxOrder(items[i].Taxes[a]) < taxOrder(items[i].Taxes[b])
+		})
+	}
+	return nil
+}
+
+// ResolveCatalogItems melengkapi item yang merujuk ke katalog: nama yang kosong
+// dan harga yang tidak dikirim diisi dari katalog, begitu juga pajak jika
+// tax_rate_ids tidak dikirim. Nilai yang sudah terisi dianggap salinan dan tidak ditimpa, sehingga
+// perubahan katalog tidak mengubah invoice lama.
+func ResolveCatalogItems(tx *gorm.DB, userID uint, items []models.InvoiceItem) error {
+	var ids []uint
+	for _, item := range items {
+		if item.CatalogItemID != nil {
+			ids = append(ids, *item.CatalogItemID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var found []models.CatalogItem
+	if err := tx.Where("user_id = ? AND id IN ?", userID, ids).Find(&found).Error; err != nil {
+		return err
+	}
+	catalog := map[uint]models.CatalogItem{}
+	for _, entry := range found {
+		catalog[entry.ID] = entry
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.CatalogItemID == nil {
+			continue
+		}
+
+		entry, ok := catalog[*item.CatalogItemID]
+		if !ok {
+			// Item katalog yang sudah dihapus tetap boleh dirujuk oleh salinan lama
+			if item.ItemName != "" && !item.UnitPriceOmitted {
+				continue
+			}
+			return &ValidationError{Message: fmt.Sprintf("item katalog %d tidak ditemukan", *item.CatalogItemID)}
+		}
+
+		if item.ItemName == "" {
+			item.ItemName = entry.Name
+		}
+		if item.UnitPriceOmitted {
+			item.UnitPrice = entry.UnitPrice
+			item.UnitPriceOmitted = false
+		}
+		if item.TaxRateIDs == nil && entry.TaxRateID != nil {
+			item.TaxRateIDs = []uint{*entry.TaxRateID}
+		}
+	}
+	return nil
+}
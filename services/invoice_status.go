@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// TransitionError dikembalikan jika perpindahan status tidak diizinkan
+type TransitionError struct {
+	Document string // jenis dokumen, kosong = invoice
+	From     string
+	To       string
+	Allowed  []string
+}
+
+func (e *TransitionError) Error() string {
+	document := e.Document
+	if document == "" {
+		document = "invoice"
+	}
+	return fmt.Sprintf("status %s tidak bisa diubah dari %q ke %q", document, e.From, e.To)
+}
+
+// TransitionInvoice memindahkan status invoice dan mencatatnya di riwayat status.
+// userID bernilai 0 jika perubahan dilakukan oleh sistem.
+func TransitionInvoice(tx *gorm.DB, invoice *models.Invoice, to string, userID uint, note string) error {
+	from := invoice.Status
+	if !models.CanTransition(from, to) {
+		return &TransitionError{From: from, To: to, Allowed: models.AllowedTransitions(from)}
+	}
+
+	// Invoice yang sudah lunas atau dibatalkan tidak lagi overdue
+	updates := map[string]interface{}{"status": to}
+	if !models.IsPayable(to) {
+		updates["is_overdue"] = false
+	}
+	if err := tx.Model(invoice).Updates(updates).Error; err != nil {
+		return err
+	}
+	invoice.Status = to
+	if !models.IsPayable(to) {
+		invoice.IsOverdue = false
+	}
+
+	return RecordInvoiceStatus(tx, invoice.ID, from, to, userID, note)
+}
+
+// RecordInvoiceStatus menyimpan satu baris riwayat status invoice
+func RecordInvoiceStatus(tx *gorm.DB, invoiceID uint, from, to string, userID uint, note string) error {
+	history := models.InvoiceStatusHistory{
+		InvoiceID:  invoiceID,
+		FromStatus: from,
+		ToStatus:   to,
+		UserID:     userID,
+		Note:       note,
+	}
+	return tx.Create(&history).Error
+}
+
+// SyncInvoiceBalance menjumlahkan ulang pembayaran dan kredit invoice, menyimpan
+// saldonya, lalu memindahkan status pembayaran bila berubah
+func SyncInvoiceBalance(tx *gorm.DB, invoice *models.Invoice, userID uint) error {
+	var paid models.Money
+	if err := tx.Model(&models.InvoicePayment{}).
+		Where("invoice_id = ?", invoice.ID).
+		Select("COALESCE(SUM(amount), 0)::bigint").
+		Scan(&paid).Error; err != nil {
+		return err
+	}
+
+	var credited models.Money
+	if err := tx.Model(&models.CreditNoteAllocation{}).
+		Where("invoice_id = ?", invoice.ID).
+		Select("COALESCE(SUM(amount), 0)::bigint").
+		Scan(&credited).Error; err != nil {
+		return err
+	}
+
+	invoice.CreditedAmount = credited
+	invoice.UpdateBalance(paid)
+	if err := tx.Model(invoice).Select("amount_paid", "credited_amount", "balance").Updates(invoice).Error; err != nil {
+		return err
+	}
+
+	// Invoice yang sudah di-void tetap void walaupun saldonya berubah
+	if !models.IsPayable(invoice.Status) && invoice.Status != models.StatusPaid {
+		return nil
+	}
+	if status := invoice.PaymentStatus(); status != invoice.Status {
+		return TransitionInvoice(tx, invoice, status, userID, "")
+	}
+	return nil
+}
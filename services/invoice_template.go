@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/pdf"
+	"gorm.io/gorm"
+)
+
+// FindInvoiceTemplate mencari template bawaan atau template milik user
+// berdasarkan key-nya
+func FindInvoiceTemplate(tx *gorm.DB, userID uint, key string) (models.InvoiceTemplate, error) {
+	if template, ok := models.BuiltinInvoiceTemplate(key); ok {
+		return template, nil
+	}
+
+	var template models.InvoiceTemplate
+	id, err := strconv.ParseUint(key, 10, 64)
+	if err == nil {
+		err = tx.Where("id = ? AND user_id = ?", id, userID).First(&template).Error
+	}
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return template, &ValidationError{Message: "template PDF " + strconv.Quote(key) + " tidak ditemukan"}
+		}
+		return template, err
+	}
+	template.SetKey()
+	return template, nil
+}
+
+// InvoiceTemplateFor menentukan template PDF invoice: pilihan invoice, lalu
+// pengaturan user, lalu template bawaan. Template yang sudah dihapus dilewati
+// supaya PDF invoice lama tetap bisa dibuat.
+func InvoiceTemplateFor(tx *gorm.DB, invoice models.Invoice) (models.InvoiceTemplate, error) {
+	setting, err := LoadUserSetting(tx, invoice.UserID)
+	if err != nil {
+		return models.InvoiceTemplate{}, err
+	}
+
+	for _, key := range []string{invoice.PDFTemplate, setting.InvoiceTemplate} {
+		if key == "" {
+			continue
+		}
+		template, err := FindInvoiceTemplate(tx, invoice.UserID, key)
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			continue
+		}
+		return template, err
+	}
+
+	template, _ := models.BuiltinInvoiceTemplate(models.DefaultInvoiceTemplate)
+	return template, nil
+}
+
+// RenderInvoicePDF memuat penerbit, client dan template invoice lalu menulis PDF-nya ke w
+func RenderInvoicePDF(tx *gorm.DB, w io.Writer, invoice models.Invoice) error {
+	issuer, err := LoadIssuer(tx, invoice.UserID)
+	if err != nil {
+		return err
+	}
+
+	template, err := InvoiceTemplateFor(tx, invoice)
+	if err != nil {
+		return err
+	}
+
+	// Client yang sudah dihapus tetap dicetak di invoice lamanya
+	var client models.Client
+	if err := tx.Unscoped().Where("id = ?", invoice.ClientID).Limit(1).Find(&client).Error; err != nil {
+		return err
+	}
+
+	return pdf.RenderInvoice(w, invoice, issuer, client, template)
+}
+
+// sampleInvoice membuat invoice contoh untuk pratinjau template dalam mata uang dasar user
+func sampleInvoice(userID uint, currency string, today time.Time) (models.Invoice, models.Client) {
+	invoice := models.Invoice{
+		UserID:        userID,
+		Number:        "INV/CONTOH/0001",
+		IssueDate:     today.Format(dateLayout),
+		DueDate:       today.AddDate(0, 0, 14).Format(dateLayout),
+		Status:        models.StatusSent,
+		Currency:      currency,
+		BaseCurrency:  currency,
+		ExchangeRate:  1,
+		DiscountType:  models.DiscountPercent,
+		DiscountValue: 5_00,
+		Note:          "Ini adalah invoice contoh untuk pratinjau template.",
+		Items: []models.InvoiceItem{
+			{
+				ItemName:      "Jasa desain logo",
+				Quantity:      1,
+				UnitPrice:     2_500_000_00,
+				DiscountType:  models.DiscountPercent,
+				DiscountValue: 10_00,
+			},
+			{
+				ItemName:  "Hosting website (per bulan)",
+				Quantity:  12,
+				UnitPrice: 150_000_00,
+				Taxes:     []models.ItemTax{{Name: "PPN", Rate: 11}},
+			},
+			{
+				ItemName:  "Konsultasi teknis",
+				Quantity:  3,
+				UnitPrice: 750_000_00,
+				Taxes:     []models.ItemTax{{Name: "PPN", Rate: 11}},
+			},
+		},
+	}
+	invoice.CalculateTotals()
+	invoice.UpdateBalance(0)
+
+	client := models.Client{
+		Nama:  "PT Contoh Pelanggan",
+		Email: "keuangan@contoh.co.id",
+		NoTlp: "021-5550123",
+	}
+	return invoice, client
+}
+
+// RenderInvoicePreview menulis PDF invoice contoh dengan template yang diberikan
+// memakai profil usaha user sebagai penerbit
+func RenderInvoicePreview(tx *gorm.DB, w io.Writer, userID uint, template models.InvoiceTemplate) error {
+	issuer, err := LoadIssuer(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	setting, err := LoadUserSetting(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	invoice, client := sampleInvoice(userID, setting.BaseCurrency, UserToday(setting, time.Now()))
+	return pdf.RenderInvoice(w, invoice, issuer, client, template)
+}
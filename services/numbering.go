@@ -0,0 +1,83 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+// nextSequence menaikkan nomor urut secara atomik. Baris sequence terkunci sampai
+// transaksi selesai, sehingga nomor yang dibatalkan ikut di-rollback dan tidak bolong.
+func nextSequence(tx *gorm.DB, userID uint, docType, period string) (int, error) {
+	var seq int
+	err := tx.Raw(`
+		INSERT INTO document_sequences (user_id, doc_type, period, last_number, created_at, updated_at)
+		VALUES (?, ?, ?, 1, NOW(), NOW())
+		ON CONFLICT (user_id, doc_type, period)
+		DO UPDATE SET last_number = document_sequences.last_number + 1, updated_at = NOW()
+		RETURNING last_number`,
+		userID, docType, period).Scan(&seq).Error
+	return seq, err
+}
+
+// maxNumberAttempts membatasi berapa nomor terpakai yang dilewati sebelum menyerah
+const maxNumberAttempts = 1000
+
+// nextDocumentNumber mengalokasikan nomor dokumen berikutnya berdasarkan tanggal terbit
+// dengan pola dari pengaturan user. Nomor yang sudah dipakai dokumen lain milik
+// user (misalnya setelah pola atau periode reset diganti) dilewati.
+func nextDocumentNumber(tx *gorm.DB, userID uint, docType string, issueDate string, model interface{}, pattern func(models.UserSetting) string) (string, error) {
+	date, err := time.Parse("2006-01-02", issueDate)
+	if err != nil {
+		return "", err
+	}
+
+	setting, err := LoadUserSetting(tx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	period := utils.SequencePeriod(setting.InvoiceNumberReset, date)
+	for range maxNumberAttempts {
+		seq, err := nextSequence(tx, userID, docType, period)
+		if err != nil {
+			return "", err
+		}
+
+		number := utils.FormatDocumentNumber(pattern(setting), date, seq)
+		var count int64
+		if err := tx.Model(model).Unscoped().
+			Where("user_id = ? AND number = ?", userID, number).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return number, nil
+		}
+	}
+	return "", &ValidationError{Message: "nomor dokumen habis untuk pola ini, ganti pola nomor di pengaturan"}
+}
+
+// NextInvoiceNumber mengalokasikan nomor invoice berikutnya berdasarkan tanggal terbit.
+// Harus dipanggil di dalam transaksi yang sama dengan pembuatan invoice.
+func NextInvoiceNumber(tx *gorm.DB, userID uint, issueDate string) (string, error) {
+	return nextDocumentNumber(tx, userID, models.DocTypeInvoice, issueDate, &models.Invoice{}, func(s models.UserSetting) string {
+		return s.InvoiceNumberPattern
+	})
+}
+
+// NextQuoteNumber sama seperti NextInvoiceNumber dengan urutan terpisah
+func NextQuoteNumber(tx *gorm.DB, userID uint, issueDate string) (string, error) {
+	return nextDocumentNumber(tx, userID, models.DocTypeQuote, issueDate, &models.Quote{}, func(s models.UserSetting) string {
+		return s.QuoteNumberPattern
+	})
+}
+
+// NextCreditNoteNumber sama seperti NextInvoiceNumber dengan urutan terpisah
+func NextCreditNoteNumber(tx *gorm.DB, userID uint, issueDate string) (string, error) {
+	return nextDocumentNumber(tx, userID, models.DocTypeCreditNote, issueDate, &models.CreditNote{}, func(s models.UserSetting) string {
+		return s.CreditNoteNumberPattern
+	})
+}
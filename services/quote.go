@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// ErrQuoteConverted dikembalikan jika quote sudah pernah dikonversi menjadi invoice
+var ErrQuoteConverted = errors.New("quote sudah dikonversi menjadi invoice")
+
+// PrepareQuote memvalidasi tanggal lalu menghitung pajak, kurs, diskon dan total
+// quote dengan aturan yang sama seperti invoice
+func PrepareQuote(tx *gorm.DB, quote *models.Quote, opts InvoiceOptions) error {
+	issue, err := time.Parse(dateLayout, quote.IssueDate)
+	if err != nil {
+		return &ValidationError{Message: "format issue_date harus YYYY-MM-DD"}
+	}
+	expiry, err := time.Parse(dateLayout, quote.ExpiryDate)
+	if err != nil {
+		return &ValidationError{Message: "format expiry_date harus YYYY-MM-DD"}
+	}
+	if expiry.Before(issue) {
+		return &ValidationError{Message: "expiry_date tidak boleh sebelum issue_date"}
+	}
+	if len(quote.Items) == 0 {
+		return &ValidationError{Message: "quote harus memiliki minimal satu item"}
+	}
+
+	calc := quote.ToInvoice()
+	if err := PrepareInvoice(tx, &calc, opts); err != nil {
+		return err
+	}
+	quote.CopyTotals(calc)
+	return nil
+}
+
+// CreateQuote menghitung total, memberi nomor lalu menyimpan quote baru berstatus Draft
+func CreateQuote(tx *gorm.DB, quote *models.Quote, opts InvoiceOptions) error {
+	quote.Status = models.QuoteStatusDraft
+	if err := PrepareQuote(tx, quote, opts); err != nil {
+		return err
+	}
+
+	number, err := NextQuoteNumber(tx, quote.UserID, quote.IssueDate)
+	if err != nil {
+		return err
+	}
+	quote.Number = number
+
+	return tx.Create(quote).Error
+}
+
+// quoteExpired menandakan masa berlaku quote sudah lewat menurut zona waktu user
+func quoteExpired(tx *gorm.DB, quote *models.Quote) (bool, error) {
+	setting, err := LoadUserSetting(tx, quote.UserID)
+	if err != nil {
+		return false, err
+	}
+	today := UserToday(setting, time.Now()).Format(dateLayout)
+	return quote.ExpiryDate < today, nil
+}
+
+// TransitionQuote memindahkan status quote. Quote yang sudah lewat masa
+// berlakunya tidak bisa dikirim atau diterima.
+func TransitionQuote(tx *gorm.DB, quote *models.Quote, to string) error {
+	from := quote.Status
+	if !models.CanTransitionQuote(from, to) {
+		return &TransitionError{Document: "quote", From: from, To: to, Allowed: models.AllowedQuoteTransitions(from)}
+	}
+
+	if to == models.QuoteStatusSent || to == models.QuoteStatusAccepted {
+		expired, err := quoteExpired(tx, quote)
+		if err != nil {
+			return err
+		}
+		if expired {
+			return &ValidationError{Message: fmt.Sprintf("quote sudah lewat masa berlaku (%s), perpanjang expiry_date terlebih dahulu", quote.ExpiryDate)}
+		}
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": to}
+	switch to {
+	case models.QuoteStatusAccepted:
+		updates["accepted_at"] = now
+		quote.AcceptedAt = &now
+	case models.QuoteStatusDeclined:
+		updates["declined_at"] = now
+		quote.DeclinedAt = &now
+	}
+	if err := tx.Model(quote).Updates(updates).Error; err != nil {
+		return err
+	}
+	quote.Status = to
+	return nil
+}
+
+// ConvertQuote membuat invoice Draft dari quote dengan client, diskon, item dan
+// pajak yang sama persis, lalu menautkan keduanya. Quote yang belum diterima
+// dianggap diterima saat dikonversi. Kurs quote ikut dipakai supaya nilai
+// invoice sama dengan yang disetujui client.
+func ConvertQuote(tx *gorm.DB, quote *models.Quote, issueDate, dueDate string, actorID uint) (*models.Invoice, error) {
+	if quote.InvoiceID != nil {
+		return nil, ErrQuoteConverted
+	}
+	if quote.Status != models.QuoteStatusAccepted {
+		if err := TransitionQuote(tx, quote, models.QuoteStatusAccepted); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := time.Parse(dateLayout, dueDate); err != nil {
+		return nil, &ValidationError{Message: "format due_date harus YYYY-MM-DD"}
+	}
+
+	quoteID := quote.ID
+	invoice := quote.ToInvoice()
+	invoice.QuoteID = &quoteID
+	invoice.IssueDate = issueDate
+	invoice.DueDate = dueDate
+
+	err := CreateInvoice(tx, &invoice, InvoiceOptions{
+		Currency:      quote.Currency,
+		ExchangeRate:  quote.ExchangeRate,
+		ActorID:       actorID,
+		Note:          fmt.Sprintf("Dibuat dari quote %s", quote.Number),
+		KeepItemTaxes: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(quote).Update("invoice_id", invoice.ID).Error; err != nil {
+		return nil, err
+	}
+	quote.InvoiceID = &invoice.ID
+	return &invoice, nil
+}
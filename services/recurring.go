@@ -0,0 +1,226 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+const dateLayout = "2006-01-02"
+
+// batas iterasi pencarian jadwal, kira-kira 270 tahun untuk interval harian
+const maxOccurrences = 100000
+
+// addMonthsClamped menambah bulan tanpa meluber ke bulan berikutnya,
+// misalnya 31 Januari + 1 bulan menjadi 28/29 Februari
+func addMonthsClamped(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, t.Location())
+}
+
+// occurrence mengembalikan tanggal jadwal ke-n dihitung dari tanggal mulai,
+// supaya tanggal tidak bergeser setelah bulan pendek
+func occurrence(r *models.RecurringInvoice, start time.Time, n int) time.Time {
+	step := n * r.IntervalCount
+	switch r.IntervalUnit {
+	case models.IntervalDay:
+		return start.AddDate(0, 0, step)
+	case models.IntervalWeek:
+		return start.AddDate(0, 0, 7*step)
+	case models.IntervalYear:
+		return addMonthsClamped(start, 12*step)
+	default:
+		return addMonthsClamped(start, step)
+	}
+}
+
+// NextRunAfter mencari tanggal jadwal pertama setelah tanggal after (dan tidak
+// sebelum tanggal mulai). ok bernilai false jika jadwal sudah melewati tanggal akhir.
+func NextRunAfter(r *models.RecurringInvoice, after time.Time) (next time.Time, ok bool, err error) {
+	start, err := time.Parse(dateLayout, r.StartDate)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if r.IntervalUnit == models.IntervalCron {
+		schedule, err := cron.ParseStandard(r.CronExpr)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		from := after
+		if from.Before(start.AddDate(0, 0, -1)) {
+			from = start.AddDate(0, 0, -1)
+		}
+		// jadwal dihitung per hari, jadi mulai mencari dari akhir hari tersebut
+		next = schedule.Next(from.Add(24*time.Hour - time.Second))
+		next = time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, time.UTC)
+	} else {
+		found := false
+		for n := 0; n < maxOccurrences; n++ {
+			if next = occurrence(r, start, n); next.After(after) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return time.Time{}, false, nil
+		}
+	}
+
+	if next.IsZero() {
+		return time.Time{}, false, nil
+	}
+	if r.EndDate != "" {
+		end, err := time.Parse(dateLayout, r.EndDate)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if next.After(end) {
+			return time.Time{}, false, nil
+		}
+	}
+	return next, true, nil
+}
+
+// ValidateRecurringInvoice memeriksa jadwal, client, item dan pajak template
+func ValidateRecurringInvoice(tx *gorm.DB, r *models.RecurringInvoice) error {
+	start, err := time.Parse(dateLayout, r.StartDate)
+	if err != nil {
+		return &ValidationError{Message: "format start_date harus YYYY-MM-DD"}
+	}
+	if r.EndDate != "" {
+		end, err := time.Parse(dateLayout, r.EndDate)
+		if err != nil {
+			return &ValidationError{Message: "format end_date harus YYYY-MM-DD"}
+		}
+		if end.Before(start) {
+			return &ValidationError{Message: "end_date tidak boleh sebelum start_date"}
+		}
+	}
+
+	switch r.IntervalUnit {
+	case models.IntervalCron:
+		if _, err := cron.ParseStandard(r.CronExpr); err != nil {
+			return &ValidationError{Message: fmt.Sprintf("cron_expr tidak valid: %v", err)}
+		}
+	case models.IntervalDay, models.IntervalWeek, models.IntervalMonth, models.IntervalYear:
+		if r.IntervalCount < 1 {
+			return &ValidationError{Message: "interval_count minimal 1"}
+		}
+	default:
+		return &ValidationError{Message: "interval_unit harus day, week, month, year atau cron"}
+	}
+
+	if len(r.Items) == 0 {
+		return &ValidationError{Message: "item invoice berulang tidak boleh kosong"}
+	}
+
+	// Cek client, mata uang, pajak dan diskon dengan menyusun invoice contoh
+	sample := recurringInvoiceDraft(r, r.StartDate)
+	return PrepareInvoice(tx, &sample, InvoiceOptions{Currency: r.Currency})
+}
+
+// ScheduleFirstRun mengisi NextRunDate setelah template dibuat atau jadwalnya diubah.
+// Jadwal yang sudah pernah berjalan dilanjutkan setelah tanggal terbit terakhir.
+func ScheduleFirstRun(r *models.RecurringInvoice) error {
+	start, err := time.Parse(dateLayout, r.StartDate)
+	if err != nil {
+		return err
+	}
+
+	after := start.AddDate(0, 0, -1)
+	if last, err := time.Parse(dateLayout, r.LastRunDate); err == nil && !last.Before(after) {
+		after = last
+	}
+
+	next, ok, err := NextRunAfter(r, after)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		r.NextRunDate = ""
+		return nil
+	}
+	r.NextRunDate = next.Format(dateLayout)
+	return nil
+}
+
+func recurringInvoiceDraft(r *models.RecurringInvoice, issueDate string) models.Invoice {
+	issue, _ := time.Parse(dateLayout, issueDate)
+	recurringID := r.ID
+
+	return models.Invoice{
+		UserID:             r.UserID,
+		ClientID:           r.ClientID,
+		RecurringInvoiceID: &recurringID,
+		IssueDate:          issueDate,
+		DueDate:            issue.AddDate(0, 0, r.DueDays).Format(dateLayout),
+		Note:               r.Note,
+		DiscountType:       r.DiscountType,
+		DiscountValue:      r.DiscountValue,
+		Items:              r.InvoiceItems(),
+	}
+}
+
+// GenerateRecurringInvoice membuat invoice untuk jadwal NextRunDate lalu memajukan
+// jadwal ke tanggal berikutnya. Keduanya terjadi di transaksi yang sama sehingga
+// satu jadwal tidak pernah menghasilkan invoice ganda.
+func GenerateRecurringInvoice(tx *gorm.DB, r *models.RecurringInvoice) (*models.Invoice, error) {
+	issueDate := r.NextRunDate
+	invoice := recurringInvoiceDraft(r, issueDate)
+
+	err := CreateInvoice(tx, &invoice, InvoiceOptions{
+		Currency: r.Currency,
+		Note:     fmt.Sprintf("Dibuat otomatis dari invoice berulang #%d", r.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if r.AutoSend {
+		if err := TransitionInvoice(tx, &invoice, models.StatusSent, 0, "Dikirim otomatis"); err != nil {
+			return nil, err
+		}
+	}
+
+	current, _ := time.Parse(dateLayout, issueDate)
+	next, ok, err := NextRunAfter(r, current)
+	if err != nil {
+		return nil, err
+	}
+
+	r.LastRunDate = issueDate
+	r.LastError = ""
+	r.NextRunDate = ""
+	if ok {
+		r.NextRunDate = next.Format(dateLayout)
+	} else {
+		r.Active = false
+	}
+
+	err = tx.Model(r).Select("last_run_date", "last_error", "next_run_date", "active").Updates(r).Error
+	return &invoice, err
+}
+
+// RecurringInvoicesUsingTaxRate mengembalikan ID invoice berulang yang itemnya
+// masih memakai tarif pajak tersebut. Tarif seperti ini tidak boleh dihapus
+// karena dibaca ulang setiap kali invoice berulang dibuat.
+func RecurringInvoicesUsingTaxRate(tx *gorm.DB, userID, taxRateID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&models.RecurringInvoiceItem{}).
+		Joins("JOIN recurring_invoices ON recurring_invoices.id = recurring_invoice_items.recurring_invoice_id").
+		Where("recurring_invoices.user_id = ? AND recurring_invoices.deleted_at IS NULL", userID).
+		Where(`(CASE WHEN recurring_invoice_items.tax_rate_ids LIKE '[%' THEN recurring_invoice_items.tax_rate_ids::jsonb
+			ELSE '[]'::jsonb END) @> ?::jsonb`, fmt.Sprintf("[%d]", taxRateID)).
+		Distinct().
+		Order("recurring_invoice_items.recurring_invoice_id").
+		Pluck("recurring_invoice_items.recurring_invoice_id", &ids).Error
+	return ids, err
+}
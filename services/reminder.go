@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+// maxReminderAttempts membatasi percobaan ulang aturan yang terus gagal dikirim,
+// misalnya karena client belum punya email
+const maxReminderAttempts = 3
+
+// reminderClaimTimeout adalah batas waktu pengingat berstatus pending. Klaim
+// yang lebih lama dari ini dianggap gagal (misalnya proses mati saat mengirim)
+// dan dihitung sebagai satu percobaan.
+const reminderClaimTimeout = time.Hour
+
+// PreparedReminder adalah pengingat yang sudah diklaim dan email yang siap
+// dikirim lewat DeliverReminder
+type PreparedReminder struct {
+	Reminder models.InvoiceReminder
+	Mail     utils.Mail
+}
+
+// DaysPastDue menghitung selisih hari today terhadap jatuh tempo invoice,
+// negatif jika belum jatuh tempo
+func DaysPastDue(invoice models.Invoice, today time.Time) (int, bool) {
+	due, err := time.Parse(dateLayout, invoice.DueDate)
+	if err != nil {
+		return 0, false
+	}
+	return int(today.Sub(due).Hours() / 24), true
+}
+
+// ClaimDueReminder mengklaim pengingat untuk aturan paling akhir yang sudah
+// jatuh jadwal, selama aturan itu (atau yang lebih akhir) belum pernah terkirim
+// atau sedang dikirim. Dipanggil saat invoice masih terkunci; email-nya dikirim
+// setelah transaksi selesai lewat DeliverReminder. Mengembalikan nil jika tidak
+// ada yang perlu dikirim. Invoice atau template yang gagal dirender langsung
+// dicatat sebagai pengingat gagal.
+func ClaimDueReminder(tx *gorm.DB, invoice *models.Invoice, rules []models.ReminderRule, today time.Time) (*PreparedReminder, error) {
+	// Invoice yang sudah lunas atau dibatalkan tidak diingatkan lagi
+	if !models.IsPayable(invoice.Status) {
+		return nil, nil
+	}
+
+	days, ok := DaysPastDue(*invoice, today)
+	if !ok {
+		return nil, nil
+	}
+	rule, ok := models.DueReminderRule(rules, days)
+	if !ok {
+		return nil, nil
+	}
+
+	claimCutoff := time.Now().Add(-reminderClaimTimeout)
+
+	var sent int64
+	if err := tx.Model(&models.InvoiceReminder{}).
+		Where("invoice_id = ? AND offset_days >= ?", invoice.ID, rule.OffsetDays).
+		Where("status = ? OR (status = ? AND created_at >= ?)", models.EmailStatusSent, models.EmailStatusPending, claimCutoff).
+		Count(&sent).Error; err != nil {
+		return nil, err
+	}
+	if sent > 0 {
+		return nil, nil
+	}
+
+	// Klaim pending yang kedaluwarsa ikut dihitung supaya tidak dicoba tanpa batas
+	var failed int64
+	if err := tx.Model(&models.InvoiceReminder{}).
+		Where("invoice_id = ? AND reminder_rule_id = ?", invoice.ID, rule.ID).
+		Where("status = ? OR (status = ? AND created_at < ?)", models.EmailStatusFailed, models.EmailStatusPending, claimCutoff).
+		Count(&failed).Error; err != nil {
+		return nil, err
+	}
+	if failed >= maxReminderAttempts {
+		return nil, nil
+	}
+
+	reminder := models.InvoiceReminder{
+		InvoiceID:      invoice.ID,
+		ReminderRuleID: rule.ID,
+		OffsetDays:     rule.OffsetDays,
+		Status:         models.EmailStatusPending,
+	}
+
+	// Invoice yang tidak bisa dikirim tetap dicatat supaya terlihat di riwayat
+	m, err := loadInvoiceMail(tx, invoice)
+	var validationErr *ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		return nil, err
+	}
+
+	var mail utils.Mail
+	if err == nil {
+		reminder.Recipient = m.client.Email
+		m.data.DaysOverdue = max(days, 0)
+		subjectTemplate, bodyTemplate := rule.Templates()
+		mail, err = m.render(subjectTemplate, bodyTemplate)
+	}
+	if err != nil {
+		reminder.Status = models.EmailStatusFailed
+		reminder.Error = err.Error()
+		if err := tx.Create(&reminder).Error; err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	reminder.Subject = mail.Subject
+	if err := tx.Create(&reminder).Error; err != nil {
+		return nil, err
+	}
+	return &PreparedReminder{Reminder: reminder, Mail: mail}, nil
+}
+
+// DeliverReminder mengirim email pengingat yang sudah diklaim lalu mencatat
+// hasilnya. Dipanggil di luar transaksi yang mengunci invoice.
+func DeliverReminder(db *gorm.DB, prepared *PreparedReminder) error {
+	reminder := &prepared.Reminder
+	sendErr := utils.SendMail(prepared.Mail)
+
+	reminder.Status = models.EmailStatusSent
+	if sendErr != nil {
+		reminder.Status = models.EmailStatusFailed
+		reminder.Error = sendErr.Error()
+	}
+
+	if err := db.Model(reminder).Updates(map[string]interface{}{
+		"status": reminder.Status,
+		"error":  reminder.Error,
+	}).Error; err != nil {
+		return err
+	}
+	if sendErr != nil {
+		return &DeliveryError{Err: sendErr}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// issuedStatuses adalah status invoice yang dihitung sebagai pendapatan
+var issuedStatuses = []string{models.StatusSent, models.StatusPartiallyPaid, models.StatusPaid}
+
+// baseNetAmount adalah nilai invoice dalam mata uang dasar setelah dikurangi credit note
+const baseNetAmount = "invoices.base_amount - ROUND(COALESCE(invoices.credited_amount, 0) * invoices.exchange_rate)"
+
+type MonthlyRevenue struct {
+	Month     string       `json:"month"`     // YYYY-MM
+	Invoiced  models.Money `json:"invoiced"`  // nilai invoice terbit di bulan ini
+	Collected models.Money `json:"collected"` // pembayaran yang diterima di bulan ini
+}
+
+type ClientRevenue struct {
+	ClientID     uint         `json:"client_id"`
+	Name         string       `json:"name"`
+	InvoiceCount int64        `json:"invoice_count"`
+	Revenue      models.Money `json:"revenue"`
+	Outstanding  models.Money `json:"outstanding"`
+}
+
+type ReportSummary struct {
+	From             string           `json:"from"`
+	To               string           `json:"to"`
+	BaseCurrency     string           `json:"base_currency"`
+	Revenue          models.Money     `json:"revenue"`
+	Collected        models.Money     `json:"collected"`
+	TotalOutstanding models.Money     `json:"total_outstanding"`
+	TotalOverdue     models.Money     `json:"total_overdue"`
+	PaidCount        int64            `json:"paid_count"`
+	UnpaidCount      int64            `json:"unpaid_count"`
+	OverdueCount     int64            `json:"overdue_count"`
+	ExcludedCount    int64            `json:"excluded_count"` // invoice dengan mata uang dasar lama
+	RevenueByMonth   []MonthlyRevenue `json:"revenue_by_month"`
+	TopClients       []ClientRevenue  `json:"top_clients"`
+}
+
+// BuildReportSummary menghitung ringkasan pendapatan dan piutang invoice yang
+// terbit antara from dan to (inklusif, YYYY-MM-DD). Semua nominal dalam mata
+// uang dasar user saat ini. Invoice yang dibuat sebelum mata uang dasar diganti
+// tidak bisa dijumlahkan dan hanya dihitung di ExcludedCount.
+func BuildReportSummary(tx *gorm.DB, userID uint, from, to string, topClients int) (ReportSummary, error) {
+	setting, err := LoadUserSetting(tx, userID)
+	if err != nil {
+		return ReportSummary{}, err
+	}
+	summary := ReportSummary{From: from, To: to, BaseCurrency: setting.BaseCurrency}
+
+	issued := func() *gorm.DB {
+		return tx.Model(&models.Invoice{}).
+			Where("invoices.user_id = ? AND invoices.status IN ?", userID, issuedStatuses).
+			Where("invoices.issue_date BETWEEN ? AND ?", from, to).
+			Where("invoices.base_currency = ?", setting.BaseCurrency)
+	}
+
+	// Total dan jumlah invoice per status dalam satu query
+	var totals struct {
+		Revenue      models.Money
+		Outstanding  models.Money
+		Overdue      models.Money
+		PaidCount    int64
+		UnpaidCount  int64
+		OverdueCount int64
+	}
+	err = issued().Select(
+		"COALESCE(SUM("+baseNetAmount+"), 0)::bigint AS revenue, "+
+			"COALESCE(SUM(ROUND(balance * exchange_rate)) FILTER (WHERE status <> ?), 0)::bigint AS outstanding, "+
+			"COALESCE(SUM(ROUND(balance * exchange_rate)) FILTER (WHERE is_overdue), 0)::bigint AS overdue, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS paid_count, "+
+			"COUNT(*) FILTER (WHERE status <> ?) AS unpaid_count, "+
+			"COUNT(*) FILTER (WHERE is_overdue) AS overdue_count",
+		models.StatusPaid, models.StatusPaid, models.StatusPaid,
+	).Scan(&totals).Error
+	if err != nil {
+		return summary, err
+	}
+	summary.Revenue = totals.Revenue
+	summary.TotalOutstanding = totals.Outstanding
+	summary.TotalOverdue = totals.Overdue
+	summary.PaidCount = totals.PaidCount
+	summary.UnpaidCount = totals.UnpaidCount
+	summary.OverdueCount = totals.OverdueCount
+
+	if err := tx.Model(&models.Invoice{}).
+		Where("user_id = ? AND status IN ? AND issue_date BETWEEN ? AND ?", userID, issuedStatuses, from, to).
+		Where("base_currency <> ?", setting.BaseCurrency).
+		Count(&summary.ExcludedCount).Error; err != nil {
+		return summary, err
+	}
+
+	// Nilai invoice per bulan terbit
+	var invoiced []struct {
+		Month  string
+		Amount models.Money
+	}
+	if err := issued().
+		Select("LEFT(issue_date, 7) AS month, COALESCE(SUM(" + baseNetAmount + "), 0)::bigint AS amount").
+		Group("month").
+		Scan(&invoiced).Error; err != nil {
+		return summary, err
+	}
+
+	// Pembayaran per bulan diterima, dikonversi dengan kurs invoice
+	var collected []struct {
+		Month  string
+		Amount models.Money
+	}
+	if err := tx.Table("invoice_payments AS p").
+		Joins("JOIN invoices AS i ON i.id = p.invoice_id AND i.deleted_at IS NULL").
+		Where("i.user_id = ? AND i.base_currency = ? AND p.payment_date BETWEEN ? AND ?", userID, setting.BaseCurrency, from, to).
+		Select("LEFT(p.payment_date, 7) AS month, COALESCE(SUM(ROUND(p.amount * i.exchange_rate)), 0)::bigint AS amount").
+		Group("month").
+		Scan(&collected).Error; err != nil {
+		return summary, err
+	}
+
+	summary.RevenueByMonth = monthRange(from, to)
+	index := map[string]int{}
+	for i, month := range summary.RevenueByMonth {
+		index[month.Month] = i
+	}
+	for _, row := range invoiced {
+		if i, ok := index[row.Month]; ok {
+			summary.RevenueByMonth[i].Invoiced = row.Amount
+		}
+	}
+	for _, row := range collected {
+		if i, ok := index[row.Month]; ok {
+			summary.RevenueByMonth[i].Collected = row.Amount
+		}
+		summary.Collected += row.Amount
+	}
+
+	// Client dengan pendapatan terbesar, client yang sudah dihapus tetap ditampilkan
+	summary.TopClients = []ClientRevenue{}
+	if topClients > 0 {
+		if err := issued().
+			Joins("LEFT JOIN clients ON clients.id = invoices.client_id").
+			Select("invoices.client_id, COALESCE(clients.nama, '') AS name, COUNT(*) AS invoice_count, " +
+				"COALESCE(SUM(" + baseNetAmount + "), 0)::bigint AS revenue, " +
+				"COALESCE(SUM(ROUND(invoices.balance * invoices.exchange_rate)), 0)::bigint AS outstanding").
+			Group("invoices.client_id, clients.nama").
+			Order("revenue DESC, invoices.client_id").
+			Limit(topClients).
+			Scan(&summary.TopClients).Error; err != nil {
+			return summary, err
+		}
+	}
+
+	return summary, nil
+}
+
+// monthRange mengembalikan setiap bulan antara from dan to dengan nilai nol
+func monthRange(from, to string) []MonthlyRevenue {
+	start, err1 := time.Parse(dateLayout, from)
+	end, err2 := time.Parse(dateLayout, to)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	months := []MonthlyRevenue{}
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, MonthlyRevenue{Month: m.Format("2006-01")})
+	}
+	return months
+}
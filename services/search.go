@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// Ekspresi tsvector pencarian. Index GIN dibuat dengan ekspresi yang sama persis
+// (lihat SearchIndexes) supaya PostgreSQL memakai index. Konfigurasi 'simple'
+// dipakai karena teksnya campuran nama, email, nomor dan bahasa Indonesia.
+const (
+	clientSearchVector      = `to_tsvector('simple', COALESCE(clients.nama, '') || ' ' || COALESCE(clients.email, '') || ' ' || COALESCE(clients.no_tlp, ''))`
+	invoiceSearchVector     = `to_tsvector('simple', COALESCE(invoices.number, '') || ' ' || COALESCE(invoices.note, ''))`
+	invoiceItemSearchVector = `to_tsvector('simple', COALESCE(invoice_items.item_name, ''))`
+)
+
+// SearchIndexes adalah perintah pembuatan index full-text, dijalankan saat migrasi
+var SearchIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_clients_search ON clients USING GIN ((` + clientSearchVector + `))`,
+	`CREATE INDEX IF NOT EXISTS idx_invoices_search ON invoices USING GIN ((` + invoiceSearchVector + `))`,
+	`CREATE INDEX IF NOT EXISTS idx_invoice_items_search ON invoice_items USING GIN ((` + invoiceItemSearchVector + `))`,
+}
+
+// Jenis hasil pencarian
+const (
+	SearchTypeClient  = "client"
+	SearchTypeInvoice = "invoice"
+)
+
+type SearchHit struct {
+	Type         string        `json:"type"`
+	ID           uint          `json:"id"`
+	Title        string        `json:"title"`    // nama client atau nomor invoice
+	Subtitle     string        `json:"subtitle"` // email client atau nama client invoice
+	Rank         float64       `json:"rank"`
+	Status       string        `json:"status,omitempty"`
+	Currency     string        `json:"currency,omitempty"`
+	Amount       *models.Money `json:"amount,omitempty"`
+	MatchedItems []string      `json:"matched_items,omitempty"` // item invoice yang cocok
+}
+
+// searchTSQuery mengubah input bebas menjadi tsquery prefix, misalnya
+// "budi konsul" menjadi 'budi':* & 'konsul':*. Karakter selain huruf, angka,
+// @ . - _ / dianggap pemisah kata supaya input tidak bisa mengubah sintaks tsquery.
+func searchTSQuery(q string) string {
+	var terms []string
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("@.-_/", r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, q)
+	for _, word := range strings.Fields(cleaned) {
+		word = strings.Trim(word, ".-_/")
+		if word != "" {
+			terms = append(terms, fmt.Sprintf("'%s':*", word))
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// Search mencari client (nama, email, telepon) dan invoice (nomor, catatan,
+// nama item) milik user, diurutkan dari yang paling relevan. types membatasi
+// jenis hasil, kosong berarti semua jenis.
+func Search(tx *gorm.DB, userID uint, q string, types []string, limit int) ([]SearchHit, error) {
+	hits := []SearchHit{}
+	tsquery := searchTSQuery(q)
+	if tsquery == "" {
+		return hits, nil
+	}
+
+	want := func(t string) bool {
+		if len(types) == 0 {
+			return true
+		}
+		for _, wanted := range types {
+			if wanted == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	if want(SearchTypeClient) {
+		var clients []struct {
+			ID    uint
+			Nama  string
+			Email string
+			Rank  float64
+		}
+		err := tx.Model(&models.Client{}).
+			Select("clients.id, clients.nama, clients.email, ts_rank("+clientSearchVector+", to_tsquery('simple', ?)) AS rank", tsquery).
+			Where("clients.user_id = ?", userID).
+			Where(clientSearchVector+" @@ to_tsquery('simple', ?)", tsquery).
+			Order("rank DESC, clients.id DESC").
+			Limit(limit).
+			Scan(&clients).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, client := range clients {
+			hits = append(hits, SearchHit{
+				Type:     SearchTypeClient,
+				ID:       client.ID,
+				Title:    client.Nama,
+				Subtitle: client.Email,
+				Rank:     client.Rank,
+			})
+		}
+	}
+
+	if want(SearchTypeInvoice) {
+		// Invoice cocok lewat nomor/catatan atau lewat salah satu itemnya
+		var invoices []struct {
+			ID           uint
+			Number       string
+			Status       string
+			Currency     string
+			Amount       models.Money
+			ClientName   string
+			MatchedItems string
+			Rank         float64
+		}
+		err := tx.Model(&models.Invoice{}).
+			Select(`invoices.id, invoices.number, invoices.status, invoices.currency, invoices.amount,
+				COALESCE(clients.nama, '') AS client_name,
+				COALESCE(string_agg(DISTINCT invoice_items.item_name, chr(31)), '') AS matched_items,
+				GREATEST(ts_rank(`+invoiceSearchVector+`, to_tsquery('simple', @q)),
+					COALESCE(MAX(ts_rank(`+invoiceItemSearchVector+`, to_tsquery('simple', @q))), 0)) AS rank`,
+				map[string]interface{}{"q": tsquery}).
+			Joins("LEFT JOIN clients ON clients.id = invoices.client_id").
+			Joins("LEFT JOIN invoice_items ON invoice_items.invoice_id = invoices.id AND "+
+				invoiceItemSearchVector+" @@ to_tsquery('simple', ?)", tsquery).
+			Where("invoices.user_id = ?", userID).
+			Where("("+invoiceSearchVector+" @@ to_tsquery('simple', ?) OR invoice_items.id IS NOT NULL)", tsquery).
+			Group("invoices.id, clients.nama").
+			Order("rank DESC, invoices.id DESC").
+			Limit(limit).
+			Scan(&invoices).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, invoice := range invoices {
+			amount := invoice.Amount
+			hit := SearchHit{
+				Type:     SearchTypeInvoice,
+				ID:       invoice.ID,
+				Title:    invoice.Number,
+				Subtitle: invoice.ClientName,
+				Rank:     invoice.Rank,
+				Status:   invoice.Status,
+				Currency: invoice.Currency,
+				Amount:   &amount,
+			}
+			if invoice.MatchedItems != "" {
+				hit.MatchedItems = strings.Split(invoice.MatchedItems, "\x1f")
+			}
+			hits = append(hits, hit)
+		}
+	}
+
+	// Gabungkan kedua jenis berdasarkan relevansi
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Rank > hits[j].Rank })
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"gorm.io/gorm"
+)
+
+// LoadUserSetting mengambil pengaturan user, atau pengaturan bawaan jika belum ada
+func LoadUserSetting(tx *gorm.DB, userID uint) (models.UserSetting, error) {
+	setting := models.UserSetting{UserID: userID}
+	err := tx.Where("user_id = ?", userID).Limit(1).Find(&setting).Error
+	setting.ApplyDefaults()
+	return setting, err
+}
+
+// UserToday mengembalikan tanggal hari ini menurut zona waktu user
+func UserToday(setting models.UserSetting, now time.Time) time.Time {
+	loc, err := time.LoadLocation(setting.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	y, m, d := now.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
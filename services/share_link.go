@@ -0,0 +1,115 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sholllll662/invoice-backend/models"
+	"github.com/sholllll662/invoice-backend/utils"
+	"gorm.io/gorm"
+)
+
+// DefaultShareLinkDays adalah masa berlaku tautan publik jika tidak ditentukan
+const DefaultShareLinkDays = 30
+
+// ErrShareLinkInvalid dipakai untuk semua tautan yang tidak bisa dibuka, supaya
+// pengunjung tidak bisa membedakan tautan dicabut, kedaluwarsa atau palsu
+var ErrShareLinkInvalid = errors.New("tautan invoice tidak valid atau sudah kedaluwarsa")
+
+// isShareable menandakan invoice boleh dibuka lewat tautan publik: sudah
+// dikirim dan tidak di-void atau dibatalkan
+func isShareable(status string) bool {
+	return status != "" && status != models.StatusDraft && status != models.StatusVoid && status != models.StatusCancelled
+}
+
+// CreateShareLink membuat tautan publik baru untuk invoice yang berlaku days hari
+func CreateShareLink(tx *gorm.DB, invoice *models.Invoice, days int) (models.InvoiceShareLink, error) {
+	if invoice.Status == models.StatusDraft {
+		return models.InvoiceShareLink{}, &ValidationError{Message: "invoice draft belum bisa dibagikan, kirim invoice terlebih dahulu"}
+	}
+	if !isShareable(invoice.Status) {
+		return models.InvoiceShareLink{}, &ValidationError{Message: "invoice berstatus " + invoice.Status + " tidak bisa dibagikan"}
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return models.InvoiceShareLink{}, err
+	}
+
+	link := models.InvoiceShareLink{
+		InvoiceID: invoice.ID,
+		UserID:    invoice.UserID,
+		Nonce:     hex.EncodeToString(nonce),
+		ExpiresAt: time.Now().AddDate(0, 0, days).Truncate(time.Second),
+	}
+	if err := tx.Create(&link).Error; err != nil {
+		return link, err
+	}
+
+	err := AttachShareToken(&link)
+	return link, err
+}
+
+// AttachShareToken mengisi Token dan URL tautan. URL memakai PUBLIC_BASE_URL
+// jika diisi, selain itu hanya path-nya.
+func AttachShareToken(link *models.InvoiceShareLink) error {
+	token, err := utils.GenerateShareToken(link.ID, link.Nonce, link.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	link.Token = token
+	link.URL = strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/") + "/public/invoices/" + token
+	return nil
+}
+
+// OpenShareLink memvalidasi token tautan publik lalu mencatat kunjungannya
+func OpenShareLink(tx *gorm.DB, token string, now time.Time) (models.InvoiceShareLink, error) {
+	var link models.InvoiceShareLink
+
+	claims, err := utils.ValidateShareToken(token)
+	if err != nil {
+		return link, ErrShareLinkInvalid
+	}
+
+	if err := tx.First(&link, claims.LinkID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return link, ErrShareLinkInvalid
+		}
+		return link, err
+	}
+	if subtle.ConstantTimeCompare([]byte(link.Nonce), []byte(claims.Nonce)) != 1 || !link.IsActive(now) {
+		return link, ErrShareLinkInvalid
+	}
+
+	// Tautan lama ikut tidak berlaku setelah invoice di-void, dibatalkan atau dihapus
+	var statuses []string
+	if err := tx.Model(&models.Invoice{}).Where("id = ?", link.InvoiceID).
+		Pluck("status", &statuses).Error; err != nil {
+		return link, err
+	}
+	if len(statuses) == 0 || !isShareable(statuses[0]) {
+		return link, ErrShareLinkInvalid
+	}
+
+	err = tx.Model(&link).Updates(map[string]interface{}{
+		"first_viewed_at": gorm.Expr("COALESCE(first_viewed_at, ?)", now),
+		"last_viewed_at":  now,
+		"view_count":      gorm.Expr("view_count + 1"),
+	}).Error
+	if err != nil {
+		return link, err
+	}
+
+	if link.FirstViewedAt == nil {
+		link.FirstViewedAt = &now
+	}
+	link.LastViewedAt = &now
+	link.ViewCount++
+	return link, nil
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Audience token portal client, dipisah dari token login User
+const (
+	ClientPortalAudience = "client-portal"
+	ClientInviteAudience = "client-invite"
+)
+
+type ClientClaim struct {
+	AccountID uint   `json:"account_id"`
+	ClientID  uint   `json:"client_id"`
+	Nonce     string `json:"nonce,omitempty"` // hanya untuk token undangan
+	jwt.RegisteredClaims
+}
+
+func generateClientToken(claims *ClientClaim) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secretKey)
+}
+
+// GenerateClientToken membuat token login portal client
+func GenerateClientToken(accountID, clientID uint) (string, error) {
+	return generateClientToken(&ClientClaim{
+		AccountID: accountID,
+		ClientID:  clientID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{ClientPortalAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+		},
+	})
+}
+
+// GenerateClientInviteToken membuat token undangan portal yang hanya bisa dipakai
+// selama nonce-nya masih tersimpan di akun
+func GenerateClientInviteToken(accountID, clientID uint, nonce string, expiresAt time.Time) (string, error) {
+	return generateClientToken(&ClientClaim{
+		AccountID: accountID,
+		ClientID:  clientID,
+		Nonce:     nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{ClientInviteAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+}
+
+// ValidateClientToken memvalidasi token portal dengan audience yang diharapkan
+func ValidateClientToken(tokenStr string, audience string) (*ClientClaim, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &ClientClaim{}, func(token *jwt.Token) (interface{}, error) {
+		return secretKey, nil
+	}, jwt.WithAudience(audience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*ClientClaim)
+	if !ok || !token.Valid || claims.AccountID == 0 {
+		return nil, errors.New("token portal tidak valid")
+	}
+	return claims, nil
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+type currencyInfo struct {
+	Symbol   string
+	Decimals int // jumlah desimal yang ditampilkan
+}
+
+var currencies = map[string]currencyInfo{
+	"IDR": {Symbol: "Rp", Decimals: 0},
+	"USD": {Symbol: "$", Decimals: 2},
+	"SGD": {Symbol: "S$", Decimals: 2},
+	"MYR": {Symbol: "RM", Decimals: 2},
+	"AUD": {Symbol: "A$", Decimals: 2},
+	"EUR": {Symbol: "EUR", Decimals: 2},
+	"JPY": {Symbol: "JPY", Decimals: 0},
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency mengubah kode mata uang ke huruf besar, "" jika formatnya salah
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !currencyCode.MatchString(code) {
+		return ""
+	}
+	return code
+}
+
+// CurrencySymbol mengembalikan simbol mata uang, atau kodenya jika tidak dikenal
+func CurrencySymbol(code string) string {
+	if info, ok := currencies[code]; ok {
+		return info.Symbol
+	}
+	return code
+}
+
+// CurrencyDecimals mengembalikan jumlah desimal minimum yang ditampilkan
+func CurrencyDecimals(code string) int {
+	if info, ok := currencies[code]; ok {
+		return info.Decimals
+	}
+	return 2
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var seqPattern = regexp.MustCompile(`\{seq(?::(0+))?\}`)
+
+// ValidateNumberPattern memastikan pola nomor dokumen memuat {seq} dan token
+// periode sesuai reset. Tanpa token periode, nomor urut yang mulai lagi dari 1
+// di periode baru menghasilkan nomor yang sudah pernah dipakai.
+func ValidateNumberPattern(pattern, reset string) error {
+	if !seqPattern.MatchString(pattern) {
+		return errors.New("pola nomor harus memuat {seq} atau {seq:0000}")
+	}
+
+	hasYear := strings.Contains(pattern, "{YYYY}") || strings.Contains(pattern, "{YY}")
+	switch reset {
+	case "monthly":
+		if !hasYear || !strings.Contains(pattern, "{MM}") {
+			return errors.New("pola nomor dengan reset bulanan harus memuat {YYYY} atau {YY} dan {MM}")
+		}
+	default:
+		if !hasYear {
+			return errors.New("pola nomor dengan reset tahunan harus memuat {YYYY} atau {YY}")
+		}
+	}
+	return nil
+}
+
+// FormatDocumentNumber membentuk nomor dokumen dari pola, misalnya
+// "INV/{YYYY}/{MM}/{seq:0000}" menjadi "INV/2025/03/0007"
+func FormatDocumentNumber(pattern string, date time.Time, seq int) string {
+	number := strings.NewReplacer(
+		"{YYYY}", date.Format("2006"),
+		"{YY}", date.Format("06"),
+		"{MM}", date.Format("01"),
+		"{DD}", date.Format("02"),
+	).Replace(pattern)
+
+	return seqPattern.ReplaceAllStringFunc(number, func(token string) string {
+		width := len(seqPattern.FindStringSubmatch(token)[1])
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+}
+
+// SequencePeriod mengembalikan kunci periode reset nomor urut
+func SequencePeriod(reset string, date time.Time) string {
+	if reset == "monthly" {
+		return date.Format("2006-01")
+	}
+	return date.Format("2006")
+}
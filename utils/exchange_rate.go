@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ratePoint adalah nilai 1 unit mata uang dalam mata uang referensi pada tanggal tertentu
+type ratePoint struct {
+	Date time.Time
+	Rate float64
+}
+
+type exchangeRateFile struct {
+	Base  string `json:"base"`
+	Rates []struct {
+		Date     string  `json:"date"`
+		Currency string  `json:"currency"`
+		Rate     float64 `json:"rate"`
+	} `json:"rates"`
+}
+
+var (
+	rateMu        sync.RWMutex
+	rateReference = "IDR"
+	rateTable     = map[string][]ratePoint{}
+)
+
+// LoadExchangeRates membaca kurs dari file lokal karena tidak ada feed kurs online.
+//
+// Format CSV: header "date,currency,rate", misalnya "2025-01-02,USD,16250".
+// Mata uang referensinya diambil dari reference (default IDR).
+// Format JSON: {"base": "IDR", "rates": [{"date": "2025-01-02", "currency": "USD", "rate": 16250}]}.
+// rate adalah nilai 1 unit mata uang dalam mata uang referensi.
+func LoadExchangeRates(path, reference string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if reference == "" {
+		reference = "IDR"
+	}
+
+	table := map[string][]ratePoint{}
+	add := func(date, currency string, rate float64) error {
+		d, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+		if err != nil {
+			return fmt.Errorf("tanggal kurs %q tidak valid", date)
+		}
+		code := NormalizeCurrency(currency)
+		if code == "" || rate <= 0 {
+			return fmt.Errorf("kurs %s %q tidak valid", currency, date)
+		}
+		table[code] = append(table[code], ratePoint{Date: d, Rate: rate})
+		return nil
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var data exchangeRateFile
+		if err := json.NewDecoder(f).Decode(&data); err != nil {
+			return err
+		}
+		if data.Base != "" {
+			reference = data.Base
+		}
+		for _, r := range data.Rates {
+			if err := add(r.Date, r.Currency, r.Rate); err != nil {
+				return err
+			}
+		}
+	} else {
+		reader := csv.NewReader(f)
+		reader.TrimLeadingSpace = true
+		line := 0
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			line++
+			// lewati header
+			if line == 1 && strings.EqualFold(record[0], "date") {
+				continue
+			}
+			if len(record) < 3 {
+				return fmt.Errorf("baris %d: kolom kurang", line)
+			}
+			rate, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+			if err != nil {
+				return fmt.Errorf("baris %d: kurs tidak valid", line)
+			}
+			if err := add(record[0], record[1], rate); err != nil {
+				return fmt.Errorf("baris %d: %w", line, err)
+			}
+		}
+	}
+
+	for code := range table {
+		sort.Slice(table[code], func(i, j int) bool {
+			return table[code][i].Date.Before(table[code][j].Date)
+		})
+	}
+
+	rateMu.Lock()
+	rateReference = NormalizeCurrency(reference)
+	rateTable = table
+	rateMu.Unlock()
+	return nil
+}
+
+// rateOn mengembalikan kurs terakhir pada atau sebelum tanggal tertentu
+func rateOn(code string, date time.Time) (float64, bool) {
+	if code == rateReference {
+		return 1, true
+	}
+
+	points := rateTable[code]
+	idx := sort.Search(len(points), func(i int) bool {
+		return points[i].Date.After(date)
+	})
+	if idx == 0 {
+		return 0, false
+	}
+	return points[idx-1].Rate, true
+}
+
+// ExchangeRate mengembalikan nilai 1 unit from dalam mata uang to pada tanggal tertentu
+func ExchangeRate(from, to string, date time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	rateMu.RLock()
+	defer rateMu.RUnlock()
+
+	fromRate, ok := rateOn(from, date)
+	if !ok {
+		return 0, fmt.Errorf("kurs %s untuk tanggal %s tidak tersedia", from, date.Format("2006-01-02"))
+	}
+	toRate, ok := rateOn(to, date)
+	if !ok {
+		return 0, fmt.Errorf("kurs %s untuk tanggal %s tidak tersedia", to, date.Format("2006-01-02"))
+	}
+
+	// dibulatkan 10 digit signifikan supaya tersimpan rapi
+	rate, _ := strconv.ParseFloat(strconv.FormatFloat(fromRate/toRate, 'g', 10, 64), 64)
+	return rate, nil
+}
+
+// ExchangeRateCurrencies mengembalikan daftar mata uang yang punya kurs
+func ExchangeRateCurrencies() []string {
+	rateMu.RLock()
+	defer rateMu.RUnlock()
+
+	codes := []string{rateReference}
+	for code := range rateTable {
+		if code != rateReference {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes[1:])
+	return codes
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"time"
 
@@ -34,6 +35,10 @@ func ValidateToken(tokenStr string) (*JWTClaim, error) {
 	})
 
 	if claims, ok := token.Claims.(*JWTClaim); ok && token.Valid {
+		// Token dengan audience (misalnya tautan publik invoice) bukan token login
+		if len(claims.Audience) > 0 {
+			return nil, errors.New("token bukan token login")
+		}
 		return claims, nil
 	} else {
 		return nil, err
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrMailerNotConfigured dikembalikan jika SMTP_HOST belum diisi
+var ErrMailerNotConfigured = errors.New("SMTP belum dikonfigurasi")
+
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type Mail struct {
+	To          string
+	Subject     string
+	Body        string // teks biasa
+	Attachments []MailAttachment
+}
+
+// SendMail mengirim email lewat SMTP yang diatur dari env SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD dan SMTP_FROM. STARTTLS dipakai jika server
+// mendukung. Untuk pengujian lokal arahkan ke MailHog: SMTP_HOST=localhost SMTP_PORT=1025.
+func SendMail(m Mail) error {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return ErrMailerNotConfigured
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	username := os.Getenv("SMTP_USERNAME")
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = username
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("SMTP_FROM tidak valid: %w", err)
+	}
+
+	recipient, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("alamat email tujuan tidak valid: %w", err)
+	}
+
+	msg, err := buildMessage(sender, recipient, m)
+	if err != nil {
+		return err
+	}
+
+	// Server tanpa autentikasi (MailHog) cukup dikosongkan username-nya
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(host, port), auth, sender.Address, []string{recipient.Address}, msg)
+}
+
+// buildMessage menyusun email multipart/mixed: isi teks lalu lampiran base64
+func buildMessage(from, to *mail.Address, m Mail) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	textPart, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	qp := quotedprintable.NewWriter(textPart)
+	text := strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range m.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Baris base64 dibatasi 76 karakter sesuai RFC 2045
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	// Baris baru di subject bisa menyisipkan header lain
+	subject := strings.Join(strings.Fields(m.Subject), " ")
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: %s\r\n", messageID(from.Address))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n", writer.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ShareTokenAudience membedakan token tautan publik dari token login
+const ShareTokenAudience = "invoice-share"
+
+type ShareClaim struct {
+	LinkID uint   `json:"link_id"`
+	Nonce  string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// GenerateShareToken menandatangani token tautan publik invoice. Token yang sama
+// selalu dihasilkan untuk link yang sama sehingga bisa ditampilkan ulang.
+func GenerateShareToken(linkID uint, nonce string, expiresAt time.Time) (string, error) {
+	claims := &ShareClaim{
+		LinkID: linkID,
+		Nonce:  nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{ShareTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secretKey)
+}
+
+func ValidateShareToken(tokenStr string) (*ShareClaim, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &ShareClaim{}, func(token *jwt.Token) (interface{}, error) {
+		return secretKey, nil
+	}, jwt.WithAudience(ShareTokenAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*ShareClaim)
+	if !ok || !token.Valid || claims.LinkID == 0 {
+		return nil, errors.New("token tautan tidak valid")
+	}
+	return claims, nil
+}
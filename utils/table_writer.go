@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Number menandai nilai sel yang berupa angka desimal, misalnya hasil Money.String(),
+// supaya ditulis sebagai angka di XLSX dan bukan teks
+type Number string
+
+// RowWriter menulis tabel baris demi baris tanpa menampung seluruh isinya di memori.
+// Nilai sel boleh string, Number, int, int64, uint, float64 atau bool.
+type RowWriter interface {
+	WriteRow(cells ...any) error
+	Close() error
+}
+
+// formulaPrefixes adalah karakter awal yang membuat Excel membaca teks sebagai formula
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula menambahkan tanda kutip di depan teks yang bisa dibaca sebagai
+// formula, misalnya nama client "=HYPERLINK(...)". Hanya untuk sel teks; Number
+// negatif tetap ditulis apa adanya.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// cellText mengubah nilai sel menjadi teks untuk CSV dan XLSX
+func cellText(cell any) string {
+	switch v := cell.(type) {
+	case string:
+		return escapeFormula(v)
+	case Number:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvRowWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+// NewCSVRowWriter menulis CSV berpemisah koma dengan baris CRLF agar langsung
+// terbaca di Excel
+func NewCSVRowWriter(w io.Writer) RowWriter {
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	return &csvRowWriter{w: writer}
+}
+
+func (c *csvRowWriter) WriteRow(cells ...any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = cellText(cell)
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+
+	// Kirim ke client secara berkala supaya memori tetap kecil
+	c.rows++
+	if c.rows%500 == 0 {
+		c.w.Flush()
+	}
+	return c.w.Error()
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}